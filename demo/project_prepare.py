@@ -0,0 +1,46 @@
+#!/usr/bin/env python3
+"""Prepare the visit extract for the SAS programs.
+
+@python Python docstrings and comments use the same notation
+"""
+
+import csv
+import subprocess
+from pathlib import Path
+
+# @python The extract is delivered as a CSV file
+EXTRACT = Path("/data/project/raw/visits.csv")
+
+
+def read_visits(path, cutoff=None):
+    """Read the visits of the extract, up to an optional cut-off date
+
+    Args:
+        path (Path): CSV file of the extract
+        cutoff: Last date of the study period, as YYYY-MM-DD
+    """
+    with open(path, newline="") as extract:
+        return [row for row in csv.DictReader(extract) if cutoff is None or row["visit_date"] <= cutoff]
+
+
+class VisitCounter:
+    """Count the visits of each patient
+
+    Args:
+        visits: Visits as read by read_visits
+    """
+
+    def __init__(self, visits):
+        self.visits = visits
+
+    def counts(self):
+        """Number of visits per patient identifier"""
+        counts = {}
+        for visit in self.visits:
+            counts[visit["id"]] = counts.get(visit["id"], 0) + 1
+        return counts
+
+
+if __name__ == "__main__":
+    # @python Hand the cleaned extract over to SAS
+    subprocess.run(["sas", "-sysin", "project_main.sas"], check=True)
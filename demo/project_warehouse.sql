@@ -0,0 +1,16 @@
+-- @sql SQL comments use the same notation,
+--      continuing over the following comment lines
+\i project_warehouse_schema.sql
+
+/* @sql Block comments work as well */
+create table if not exists warehouse.cohort as
+select r.id, r.sex, v.visit_date
+from warehouse.registry r
+join warehouse.visits v on r.id = v.id;
+
+-- @sql Counted per patient, as in the SAS summary
+with recent as (
+    select * from warehouse.cohort where visit_date >= '2020-01-01'
+)
+insert into warehouse.cohort_summary (id, visits)
+select id, count(*) from recent group by id;
@@ -0,0 +1,3 @@
+module github.com/rbisewski/gommentary
+
+go 1.13
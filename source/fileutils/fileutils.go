@@ -13,27 +13,22 @@ func ReadFileIntoStringArray(path string) ([][]string, error) {
 
 	path = strings.TrimSpace(path)
 	if path == "" {
-		panic("ReadFileIntoStringArray: passed an empty path")
+		return nil, fmt.Errorf("ReadFileIntoStringArray: passed an empty path")
 	}
 
-	// open the file and set a defer to close the file on function
-	// return / panic
+	// open the file and set a defer to close the file on function return
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			panic(err)
-		}
-	}()
+	defer file.Close()
 
 	// initialize a new CSV reader instance and read in data
 	csvReader := csv.NewReader(bufio.NewReader(file))
 	csvReader.Comment = '#'
 	records, err := csvReader.ReadAll()
 	if err != nil {
-		return nil, fmt.Errorf("Error reading file [%s]: %s",
+		return nil, fmt.Errorf("Error reading file [%s]: %w",
 			path, err)
 	}
 
@@ -46,11 +41,11 @@ func ReadFileIntoStringArray(path string) ([][]string, error) {
 }
 
 // WriteToFile ... Write string data to a file, with the option to overwrite.
-func WriteToFile(path, data string, overwrite bool) error {
+func WriteToFile(path, data string, overwrite bool) (err error) {
 
 	path = strings.TrimSpace(path)
 	if path == "" {
-		panic("WriteToFile: passed an empty path")
+		return fmt.Errorf("WriteToFile: passed an empty path")
 	}
 	if data == "" {
 		return fmt.Errorf("WriteToFile: given data is empty")
@@ -66,9 +61,10 @@ func WriteToFile(path, data string, overwrite bool) error {
 	if err != nil {
 		return err
 	}
+	// a failure to close means the data may not have been written
 	defer func() {
-		if err := file.Close(); err != nil {
-			panic(err)
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
 		}
 	}()
 
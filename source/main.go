@@ -21,6 +21,11 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+	"github.com/rbisewski/gommentary/source/parse"
+	"github.com/rbisewski/gommentary/source/render"
 )
 
 //
@@ -45,8 +50,35 @@ var (
 	// Markdown output filenames
 	OutputFiles = []string{"output-coder.md", "output-for-all.md"}
 
-	// File types with parsable comments
-	ValidFiletypes = []string{".sas", ".do"}
+	// Comma separated list of formats to render graphs in
+	GraphFormatsArgument = render.GraphFormatText
+
+	// Data dictionary CSV to reconcile with the documented variables
+	DictionaryFile = ""
+
+	// Data directory containing the datasets of the project
+	DataDirectory = ""
+
+	// Whether or not to write the audit report of external commands and hard-coded paths
+	AuditArgument = false
+
+	// Markdown audit report filename
+	AuditFile = "output-audit.md"
+
+	// Whether identifiers found in comments are flagged, redacted or neither
+	PHIModeArgument = PHIModeOff
+
+	// File of custom identifier patterns, in addition to the built-in ones
+	PHIPatternsFile = ""
+
+	// Markdown report filename of the identifiers found in comments
+	PHIReportFile = "output-phi.md"
+
+	// Whether or not to document the files that could be read when others could not
+	KeepGoingArgument = false
+
+	// File types with parsable comments, i.e. those of the registered language front-ends
+	ValidFiletypes = parse.Extensions()
 )
 
 //
@@ -77,12 +109,55 @@ func main() {
 		DocumentationDirectory = "docs"
 	}
 
+	// errors of the files that could not be read, all of which are reported
+	// before exiting, rather than stopping at the first
+	failures := make(model.ErrorList, 0)
+
 	// attempt to read the contents of the code directory
-	extractedIncludes, extractedComments, err := ReadCommentsFromAllFilesInDirectory(CodeDirectory, ValidFiletypes)
-	if err != nil {
+	project, err := parse.ReadProjectFromDirectory(CodeDirectory, ValidFiletypes)
+	if list, ok := err.(model.ErrorList); ok {
+		failures = append(failures, list...)
+	} else if err != nil {
 		fatal(err)
 	}
 
+	// merge in the externally maintained data dictionary, if any
+	if DictionaryFile != "" {
+		dictionary, err := parse.ReadDictionaryFile(DictionaryFile)
+		if err != nil {
+			failures.Add(DictionaryFile, 0, err)
+		} else {
+			project.Variables = parse.MergeDictionary(project.Variables, dictionary)
+		}
+	}
+
+	// read the variable metadata of the datasets, if any
+	if DataDirectory != "" {
+		project.Datasets, err = parse.ReadDatasetsFromDirectory(DataDirectory)
+		if err != nil {
+			failures.Add(DataDirectory, 0, err)
+		}
+	}
+
+	// unless asked to keep going, only complete documentation is written
+	if len(failures) > 0 && !KeepGoingArgument {
+		fatalFailures(failures)
+	}
+
+	// check the comments for identifiers, redacting them if requested
+	findings := make([]model.Finding, 0)
+	if PHIModeArgument != PHIModeOff {
+		detectors := append([]model.Detector{}, parse.BuiltinDetectors...)
+		if PHIPatternsFile != "" {
+			custom, err := parse.ReadDetectorsFile(PHIPatternsFile)
+			if err != nil {
+				fatal(err)
+			}
+			detectors = append(detectors, custom...)
+		}
+		findings = parse.ScanProjectForIdentifiers(&project, detectors, PHIModeArgument == PHIModeRedact)
+	}
+
 	// create the docs directory; if it already exists nothing will
 	// happen and the program will continue regardless
 	err = os.MkdirAll(DocumentationDirectory, 0644)
@@ -91,11 +166,38 @@ func main() {
 	}
 
 	// write the documentation to the docs directory
-	err = WriteDocumentation(DocumentationDirectory, OutputFiles, extractedIncludes, extractedComments)
-	if err != nil {
+	err = render.WriteDocumentation(DocumentationDirectory, OutputFiles, graphFormats(), KeepGoingArgument, project)
+	if list, ok := err.(model.ErrorList); ok {
+		failures = append(failures, list...)
+	} else if err != nil {
 		fatal(err)
 	}
 
+	// write the report of the identifiers found, warning if they were left in the docs
+	if PHIModeArgument != PHIModeOff {
+		err = render.WriteIdentifierReport(DocumentationDirectory, PHIReportFile, findings)
+		if err != nil {
+			fatal(err)
+		}
+		if PHIModeArgument == PHIModeFlag && len(findings) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: %d possible identifiers were found in the comments, see %s\n",
+				len(findings), PHIReportFile)
+		}
+	}
+
+	// write the audit report, if requested
+	if AuditArgument {
+		err = render.WriteAudit(DocumentationDirectory, AuditFile, project)
+		if err != nil {
+			fatal(err)
+		}
+	}
+
+	// the docs are incomplete if any file could not be read
+	if len(failures) > 0 {
+		fatalFailures(failures)
+	}
+
 	os.Exit(0)
 }
 
@@ -103,12 +205,31 @@ const (
 	redColor = "\x1b[31m"
 )
 
+// Modes in which identifiers found in comments are handled
+const (
+	PHIModeOff    = "off"
+	PHIModeFlag   = "flag"
+	PHIModeRedact = "redact"
+)
+
 // Fatal prints error message in red and exits to shell with code 1
 func fatal(err error) {
 	fmt.Fprintf(os.Stderr, redColor+"%s\n", err)
 	os.Exit(1)
 }
 
+// fatalFailures prints a summary of the files that could not be read in red and exits to shell with code 1
+func fatalFailures(failures model.ErrorList) {
+	fmt.Fprintf(os.Stderr, redColor+"%d errors were encountered while reading the files:\n", len(failures))
+	for _, failure := range failures {
+		fmt.Fprintf(os.Stderr, "  %s\n", failure)
+	}
+	if !KeepGoingArgument {
+		fmt.Fprintf(os.Stderr, "Use -keep-going to document the files that could be read regardless.\n")
+	}
+	os.Exit(1)
+}
+
 // Setup the program arguments
 func setupArguments() error {
 
@@ -118,6 +239,13 @@ func setupArguments() error {
 
 	flag.StringVar(&CodeDirectory, "code-dir", "", "")
 	flag.StringVar(&DocumentationDirectory, "docs-dir", "docs", "")
+	flag.StringVar(&DataDirectory, "data-dir", "", "")
+	flag.StringVar(&DictionaryFile, "dictionary", "", "")
+	flag.StringVar(&GraphFormatsArgument, "graph-formats", render.GraphFormatText, "")
+	flag.BoolVar(&AuditArgument, "audit", false, "")
+	flag.StringVar(&PHIModeArgument, "phi", PHIModeOff, "")
+	flag.StringVar(&PHIPatternsFile, "phi-patterns", "", "")
+	flag.BoolVar(&KeepGoingArgument, "keep-going", false, "")
 	flag.BoolVar(&PrintVersionArgument, "version", false, "")
 
 	flag.Parse()
@@ -130,5 +258,25 @@ func validArgument() error {
 	if CodeDirectory == "" {
 		return fmt.Errorf("Invalid code directory path. Please enter a valid path and file.")
 	}
+	for _, format := range graphFormats() {
+		if format != render.GraphFormatText && format != render.GraphFormatDOT && format != render.GraphFormatMermaid {
+			return fmt.Errorf("Invalid graph format: %s", format)
+		}
+	}
+	if PHIModeArgument != PHIModeOff && PHIModeArgument != PHIModeFlag && PHIModeArgument != PHIModeRedact {
+		return fmt.Errorf("Invalid PHI mode: %s", PHIModeArgument)
+	}
 	return nil
 }
+
+// graphFormats returns the list of graph formats given via the arguments
+func graphFormats() []string {
+	formats := make([]string, 0)
+	for _, format := range strings.Split(GraphFormatsArgument, ",") {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != "" {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
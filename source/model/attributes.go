@@ -0,0 +1,40 @@
+/*
+ * Functions for reading the |:name value| attributes of comments
+ */
+
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+// matches a leading |:name value| attribute, where the value may be quoted
+var attributeRegex = regexp.MustCompile("^:([a-zA-Z][a-zA-Z0-9_\\.]*)(?:\\s+(\"[^\"]*\"|'[^']*'|[^\\s:]\\S*))?")
+
+// ParseAttributes ... split the leading |:name value| attributes of a comment from the text following them
+//
+// Values containing whitespace must be quoted, otherwise only the first word
+// after the attribute name is taken as its value.
+func ParseAttributes(text string) ([]Attribute, string) {
+
+	attributes := make([]Attribute, 0)
+	rest := strings.TrimSpace(text)
+
+	for {
+		match := attributeRegex.FindStringSubmatch(rest)
+		if match == nil {
+			break
+		}
+
+		value := match[2]
+		if len(value) > 1 && (value[0] == '"' || value[0] == '\'') {
+			value = value[1 : len(value)-1]
+		}
+		attributes = append(attributes, Attribute{Name: strings.ToLower(match[1]), Value: value})
+
+		rest = strings.TrimSpace(rest[len(match[0]):])
+	}
+
+	return attributes, rest
+}
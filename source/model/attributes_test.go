@@ -0,0 +1,31 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseAttributes(t *testing.T) {
+	tests := []struct {
+		name           string
+		text           string
+		wantAttributes []Attribute
+		wantRest       string
+	}{
+		{"no attributes", " Just text ", []Attribute{}, "Just text"},
+		{"single word value", ":Type numeric The age", []Attribute{{Name: "type", Value: "numeric"}}, "The age"},
+		{"quoted values", ":label \"Age at visit\" :codes '1=Yes 0=No'",
+			[]Attribute{{Name: "label", Value: "Age at visit"}, {Name: "codes", Value: "1=Yes 0=No"}}, ""},
+		{"flag", ":derived :units kg Weight", []Attribute{{Name: "derived"}, {Name: "units", Value: "kg"}}, "Weight"},
+		{"dotted name", ":source.file raw.csv", []Attribute{{Name: "source.file", Value: "raw.csv"}}, ""},
+		{"colon inside text", "Ratio :1 to 2", []Attribute{}, "Ratio :1 to 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attributes, rest := ParseAttributes(tt.text)
+			if !reflect.DeepEqual(attributes, tt.wantAttributes) || rest != tt.wantRest {
+				t.Errorf("ParseAttributes() = %+v, %q, want %+v, %q", attributes, rest, tt.wantAttributes, tt.wantRest)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Functions for relating the datasets of a project to one another and to the data dictionary
+ */
+
+package model
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// extensions of the files that datasets are commonly stored in
+	datasetExtensions = map[string]bool{
+		".csv": true, ".dat": true, ".dta": true, ".sas7bdat": true, ".sav": true, ".tab": true,
+		".txt": true, ".xls": true, ".xlsx": true, ".xpt": true,
+	}
+
+	// matches the SAS |&name.| and Stata |`name'|, |$name| and |${name}| macro references, which cannot be evaluated
+	macroReferenceRegex = regexp.MustCompile("&+[a-zA-Z_][a-zA-Z0-9_]*\\.?|`[a-zA-Z_][a-zA-Z0-9_]*'|\\$\\{?[a-zA-Z_][a-zA-Z0-9_]*\\}?")
+)
+
+// LibraryPaths ... obtain the path assigned to each libref by the libname statements of the project
+func LibraryPaths(includes []Dependency) map[string]string {
+	libraries := make(map[string]string)
+	for _, incl := range includes {
+		if incl.Kind == DependencyReference && incl.Statement == "libname" {
+			libraries[strings.ToLower(incl.Name)] = incl.Path
+		}
+	}
+	return libraries
+}
+
+// DescribeDataset ... append the path of the library a two-level dataset name resides in, if known
+func DescribeDataset(name string, libraries map[string]string) string {
+	pieces := strings.SplitN(name, ".", 2)
+	if len(pieces) != 2 {
+		return name
+	}
+	path, ok := libraries[strings.ToLower(pieces[0])]
+	if !ok {
+		return name
+	}
+	return name + " (" + path + ")"
+}
+
+// DatasetNode ... obtain the name of the project lineage graph node for a given dataset
+//
+// Datasets stored in files are known by their filename alone, since the
+// directories are often given via macros that differ between SAS and Stata,
+// and SAS datasets residing in a known library by the file SAS stores them in.
+func DatasetNode(name string, filename string, libraries map[string]string) string {
+
+	if strings.HasPrefix(name, "tempfile ") {
+		return name + " (" + filepath.Base(filename) + ")"
+	}
+
+	path := macroReferenceRegex.ReplaceAllString(name, "")
+	path = strings.Replace(path, "\\", "/", -1)
+	if strings.Contains(path, "/") || datasetExtensions[strings.ToLower(filepath.Ext(path))] {
+		return strings.ToLower(filepath.Base(path))
+	}
+
+	pieces := strings.SplitN(name, ".", 2)
+	if _, ok := libraries[strings.ToLower(pieces[0])]; ok && len(pieces) == 2 {
+		return strings.ToLower(pieces[1]) + ".sas7bdat"
+	}
+
+	return name
+}
+
+// DictionaryDiscrepancies ... describe the variables documented in only one of the code and the data dictionary
+func DictionaryDiscrepancies(variables []Variable) []string {
+
+	// without a dictionary there is nothing to reconcile against
+	hasDictionary := false
+	for _, variable := range variables {
+		if variable.Dictionary != "" {
+			hasDictionary = true
+		}
+	}
+	if !hasDictionary {
+		return nil
+	}
+
+	discrepancies := make([]string, 0)
+	for _, variable := range variables {
+
+		name := variable.Name
+		if variable.Dataset != "" {
+			name += " (" + variable.Dataset + ")"
+		}
+
+		switch {
+		case variable.Dictionary == "":
+			discrepancies = append(discrepancies, fmt.Sprintf("%s: documented in %s:%d but not in the data dictionary",
+				name, variable.Filename, variable.LineNum))
+		case variable.Filename == "":
+			discrepancies = append(discrepancies, fmt.Sprintf("%s: listed in %s but not documented in the code",
+				name, variable.Dictionary))
+		}
+
+		for _, attribute := range variable.Attributes {
+			if attribute.Name == "dictionary type" {
+				discrepancies = append(discrepancies, fmt.Sprintf("%s: documented as %s in the code but %s in the data dictionary",
+					name, variable.Type, attribute.Value))
+			}
+		}
+	}
+
+	return discrepancies
+}
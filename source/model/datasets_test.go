@@ -0,0 +1,90 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLibraryPaths(t *testing.T) {
+	includes := []Dependency{
+		{Kind: DependencyReference, Statement: "libname", Name: "RAW", Path: "/data/raw"},
+		{Kind: DependencyReference, Statement: "filename", Name: "cfg", Path: "config.txt"},
+		{Kind: DependencyCommand, Statement: "x", Path: "ls"},
+	}
+	if got, want := LibraryPaths(includes), map[string]string{"raw": "/data/raw"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("LibraryPaths() = %v, want %v", got, want)
+	}
+}
+
+func TestDescribeDataset(t *testing.T) {
+	libraries := map[string]string{"raw": "/data/raw"}
+	tests := []struct {
+		name    string
+		dataset string
+		want    string
+	}{
+		{"known library", "Raw.visits", "Raw.visits (/data/raw)"},
+		{"unknown library", "work.visits", "work.visits"},
+		{"one level", "visits", "visits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DescribeDataset(tt.dataset, libraries); got != tt.want {
+				t.Errorf("DescribeDataset() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatasetNode(t *testing.T) {
+	libraries := map[string]string{"raw": "/data/raw"}
+	tests := []struct {
+		name     string
+		dataset  string
+		filename string
+		want     string
+	}{
+		{"file path", "&root./data/Visits.csv", "a.sas", "visits.csv"},
+		{"stata global", "${data}\\cohort.dta", "a.do", "cohort.dta"},
+		{"stata local", "`dir'cohort.dta", "a.do", "cohort.dta"},
+		{"known library", "RAW.Visits", "a.sas", "visits.sas7bdat"},
+		{"unknown library", "work.visits", "a.sas", "work.visits"},
+		{"tempfile", "tempfile cohort", "code/a.do", "tempfile cohort (a.do)"},
+		{"plain name", "cohort", "a.sas", "cohort"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DatasetNode(tt.dataset, tt.filename, libraries); got != tt.want {
+				t.Errorf("DatasetNode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDictionaryDiscrepancies(t *testing.T) {
+	tests := []struct {
+		name      string
+		variables []Variable
+		want      []string
+	}{
+		{"no dictionary", []Variable{{Name: "age", Filename: "a.sas", LineNum: 2}}, nil},
+		{"discrepancies", []Variable{
+			{Name: "age", Type: "numeric", Filename: "a.sas", LineNum: 2, Dictionary: "d.csv",
+				Attributes: []Attribute{{Name: "units", Value: "years"}, {Name: "dictionary type", Value: "char"}}},
+			{Name: "sex", Dataset: "cohort", Filename: "a.sas", LineNum: 3, Dictionary: "d.csv"},
+			{Name: "flag", Dataset: "cohort", Filename: "a.sas", LineNum: 4},
+			{Name: "site", Dictionary: "d.csv"},
+		}, []string{
+			"age: documented as numeric in the code but char in the data dictionary",
+			"flag (cohort): documented in a.sas:4 but not in the data dictionary",
+			"site: listed in d.csv but not documented in the code",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DictionaryDiscrepancies(tt.variables); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DictionaryDiscrepancies() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
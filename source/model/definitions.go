@@ -0,0 +1,515 @@
+/*
+ * Contains a list of structure definitions
+ */
+
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+// RawInclude object defintion
+type RawInclude struct {
+
+	// line number that the %include was obtained on
+	LineNum int
+
+	// ascii content of the given %include statement
+	Text string
+}
+
+// Languages of code that may be parsed
+const (
+	LanguageSAS       = "sas"
+	LanguageStata     = "stata"
+	LanguageR         = "r"
+	LanguageRMarkdown = "rmarkdown"
+	LanguageSPSS      = "spss"
+	LanguageSQL       = "sql"
+	LanguagePython    = "python"
+	LanguageJupyter   = "jupyter"
+	LanguageEGP       = "egp"
+)
+
+// Kinds of dependencies a file may have
+const (
+	DependencyScript    = "script"
+	DependencyMacro     = "macro"
+	DependencyAutocall  = "autocall"
+	DependencyReference = "reference"
+	DependencyCommand   = "command"
+	DependencyPackage   = "package"
+)
+
+// Dependency object definition
+type Dependency struct {
+
+	// path to the file containing the dependency
+	Filename string
+
+	// line number that the dependency was obtained on
+	LineNum int
+
+	// path that is depended on, or the command line of an external command
+	Path string
+
+	// statement used to declare the dependency, e.g. |%include| or |do|
+	Statement string
+
+	// kind of dependency, e.g. an included script or a macro library
+	Kind string
+
+	// fileref or libref assigned by a |filename| or |libname| statement, if any
+	Name string
+}
+
+// RawComment object definition
+type RawComment struct {
+
+	// line number that the comment was obtained on
+	LineNum int
+
+	// ascii content of the given comment
+	Text string
+}
+
+// Comment object definition
+type Comment struct {
+
+	// text value of keyword, as defined via the @ symbol; blank means not a keyword comment
+	Keyword string
+
+	// which keyword this comment should be grouped under
+	GroupUnder string
+
+	// path to the file the comment was found in, mostly useful for debugging purposes
+	Filename string
+
+	// index position representing the order the file was read in
+	Index int
+
+	// line number that the comment was obtained on
+	LineNum int
+
+	// part of the file the comment was found in, such as the label of an R Markdown chunk; blank means the file as a whole
+	Part string
+
+	// whether the comment is a paragraph of the prose of a document, such as a markdown cell of a notebook
+	Narrative bool
+
+	// ascii content of the given comment
+	Text string
+}
+
+// MacroParam object definition
+type MacroParam struct {
+
+	// name of the parameter
+	Name string
+
+	// default value of the parameter, if it is a keyword parameter
+	Default string
+
+	// whether the parameter is a keyword parameter, as in |name=default|
+	Keyword bool
+
+	// description of the parameter, as given in the documentation
+	Description string
+
+	// line number that the parameter was documented on
+	LineNum int
+}
+
+// Macro object definition
+type Macro struct {
+
+	// name of the macro
+	Name string
+
+	// path to the file the macro was defined in
+	Filename string
+
+	// line number that the %macro statement was obtained on
+	LineNum int
+
+	// line number that the %mend statement was obtained on
+	EndLineNum int
+
+	// parameters as given in the %macro statement
+	Params []MacroParam
+
+	// parameters as given in the documentation preceding the macro
+	DocumentedParams []MacroParam
+
+	// text of the summary section of the documentation, if any
+	Summary string
+
+	// properties given after the comma of a Stata |program define| statement
+	Properties string
+
+	// syntax statement of a Stata program, if any
+	Syntax *StataSyntax
+
+	// language the macro is written in, e.g. |r| for an R function; blank means SAS
+	Language string
+}
+
+// StataOption object definition
+type StataOption struct {
+
+	// name of the option as given in the syntax statement, e.g. |GENerate|
+	Name string
+
+	// shortest abbreviation of the option that Stata accepts
+	Abbreviation string
+
+	// type of the option argument, e.g. |string| or |real|; blank for flags
+	Type string
+
+	// default value of the option argument, if any
+	Default string
+
+	// whether the option must be specified
+	Required bool
+}
+
+// StataSyntax object definition
+type StataSyntax struct {
+
+	// text of the syntax statement
+	Text string
+
+	// specification of the variable list, e.g. |varlist(numeric)|, if any
+	Varlist string
+
+	// whether the variable list, if/in qualifiers, using and =exp are
+	// "required", "optional", or blank when not allowed
+	VarlistRequirement string
+	If                 string
+	In                 string
+	Using              string
+	Exp                string
+
+	// weight types allowed, if any
+	Weights []string
+
+	// options allowed after the comma
+	Options []StataOption
+}
+
+// MacroIssue object definition
+type MacroIssue struct {
+
+	// name of the macro the issue was found in
+	Macro string
+
+	// path to the file the macro was defined in
+	Filename string
+
+	// line number that the issue was found on
+	LineNum int
+
+	// name of the parameter the issue concerns
+	Param string
+
+	// description of the problem
+	Problem string
+}
+
+// MacroCall object definition
+type MacroCall struct {
+
+	// name of the macro being called
+	Name string
+
+	// name of the macro the call appears inside of; blank means open code
+	Caller string
+
+	// path to the file the call was found in
+	Filename string
+
+	// line number that the call was obtained on
+	LineNum int
+}
+
+// DatasetStep object definition
+type DatasetStep struct {
+
+	// path to the file the step was found in
+	Filename string
+
+	// line number that the step starts on
+	LineNum int
+
+	// line number of the last statement of the step
+	EndLineNum int
+
+	// kind of step, e.g. |data| or |proc sort|
+	Step string
+
+	// datasets or files read by the step
+	Inputs []string
+
+	// datasets or files written by the step
+	Outputs []string
+}
+
+// FormatRange object definition
+type FormatRange struct {
+
+	// range of values as written, e.g. |0-17| or |'M'|
+	Range string
+
+	// label the range is formatted as
+	Label string
+}
+
+// Format object definition
+type Format struct {
+
+	// name of the format, excluding any leading $
+	Name string
+
+	// statement used to define the format, i.e. |value|, |invalue| or |picture|
+	Type string
+
+	// whether the format applies to character values
+	Character bool
+
+	// path to the file the format was defined in
+	Filename string
+
+	// line number that the format was defined on
+	LineNum int
+
+	// ranges and their labels, in the order they were given
+	Ranges []FormatRange
+
+	// text of the comment on the same line as, or directly above, the format
+	Comment string
+}
+
+// MacroVariable object definition
+type MacroVariable struct {
+
+	// name of the macro variable
+	Name string
+
+	// statement that assigned or declared the variable, i.e. |%let| or |%global|
+	Statement string
+
+	// path to the file the variable was assigned in
+	Filename string
+
+	// line number that the variable was assigned on
+	LineNum int
+
+	// literal or expression value assigned to the variable
+	Value string
+
+	// name of the macro the assignment appears inside of, if any
+	Macro string
+
+	// whether the assignment is to a parameter or |%local| variable of that macro
+	Local bool
+
+	// text of the comment on the same line as, or directly above, the assignment
+	Comment string
+}
+
+// Reference object definition
+type Reference struct {
+
+	// name of the item being referred to
+	Name string
+
+	// path to the file the reference appears in
+	Filename string
+
+	// line number that the reference appears on
+	LineNum int
+}
+
+// Attribute object definition
+type Attribute struct {
+
+	// name of the attribute, excluding the leading colon
+	Name string
+
+	// value of the attribute, with any quotes removed
+	Value string
+}
+
+// Variable object definition
+type Variable struct {
+
+	// name of the variable
+	Name string
+
+	// dataset the variable belongs to, if known
+	Dataset string
+
+	// type of the variable, e.g. |numeric| or |character|
+	Type string
+
+	// short label of the variable
+	Label string
+
+	// longer description of the variable
+	Description string
+
+	// codes the variable takes along with their meaning, e.g. |1=Male 2=Female|
+	Codes string
+
+	// any other attributes given for the variable, e.g. |:units kg|
+	Attributes []Attribute
+
+	// path to the file the variable was documented in; blank if only in the data dictionary
+	Filename string
+
+	// line number that the variable was documented on
+	LineNum int
+
+	// path to the data dictionary CSV the variable was listed in, if any
+	Dictionary string
+}
+
+// ValueLabel object definition
+type ValueLabel struct {
+
+	// value being labelled
+	Value string
+
+	// label of the value
+	Label string
+}
+
+// ValueLabelSet object definition
+type ValueLabelSet struct {
+
+	// name of the set of value labels
+	Name string
+
+	// values and their labels, in the order stored
+	Labels []ValueLabel
+}
+
+// DatasetVariable object definition
+type DatasetVariable struct {
+
+	// name of the variable
+	Name string
+
+	// storage type of the variable, e.g. |byte|, |str20| or |numeric|
+	Type string
+
+	// number of bytes the variable is stored in, if known
+	Length int
+
+	// display format of the variable, e.g. |%9.0g|
+	Format string
+
+	// label of the variable
+	Label string
+
+	// name of the set of value labels attached to the variable, if any
+	ValueLabel string
+}
+
+// Dataset object definition
+type Dataset struct {
+
+	// path to the file the dataset is stored in
+	Filename string
+
+	// format of the file, e.g. |Stata 118|
+	Format string
+
+	// label of the dataset
+	Label string
+
+	// number of observations in the dataset
+	Observations int64
+
+	// date and time the dataset was created, if known
+	Created time.Time
+
+	// variables of the dataset, in order
+	Variables []DatasetVariable
+
+	// sets of value labels stored with the dataset
+	ValueLabels []ValueLabelSet
+}
+
+// Detector object definition
+type Detector struct {
+
+	// name of the kind of identifier, as given in the report
+	Name string
+
+	// pattern matching the identifier
+	Pattern *regexp.Regexp
+}
+
+// Finding object definition
+type Finding struct {
+
+	// name of the detector that matched
+	Detector string
+
+	// path to the file the identifier was found in
+	Filename string
+
+	// line number that the identifier was found on
+	LineNum int
+
+	// whether the identifier was redacted from the docs, rather than only flagged
+	Redacted bool
+}
+
+// Project object definition
+type Project struct {
+
+	// paths of all of the files that were read
+	Files []string
+
+	// language of each file read, by path; files whose language is not known are absent
+	Languages map[string]string
+
+	// scripts, macros, paths, references and commands the files depend on
+	Includes []Dependency
+
+	// comments obtained from the files
+	Comments []Comment
+
+	// macros defined in the files, or in the macro libraries they include
+	Macros []Macro
+
+	// macro invocations found in the files
+	MacroCalls []MacroCall
+
+	// Stata programs defined in the files
+	Programs []Macro
+
+	// steps of the files that read or write datasets
+	Steps []DatasetStep
+
+	// formats defined via PROC FORMAT
+	Formats []Format
+
+	// macro variables assigned via %let or declared via %global
+	MacroVariables []MacroVariable
+
+	// references to macro variables
+	MacroVariableReferences []Reference
+
+	// variables documented via @var comments
+	Variables []Variable
+
+	// datasets found in the data directory
+	Datasets []Dataset
+
+	// absolute paths hard-coded in live code
+	Paths []Reference
+}
@@ -0,0 +1,83 @@
+/*
+ * Errors carrying the file and line they were found at, and lists of them
+ */
+
+package model
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Error ... an error found in a given file, at a given line if known
+type Error struct {
+
+	// path to the file the error was found in
+	Path string
+
+	// line number that the error was found on; zero means the file as a whole
+	Line int
+
+	// underlying error
+	Err error
+}
+
+// Error ... describe the error, prefixed by its position as in |path:line: message|
+func (e *Error) Error() string {
+
+	// errors of the os package already name the path
+	message := e.Err.Error()
+	if pathErr, ok := e.Err.(*os.PathError); ok && pathErr.Path == e.Path {
+		message = pathErr.Op + ": " + pathErr.Err.Error()
+	}
+
+	if e.Line > 0 {
+		return e.Path + ":" + strconv.Itoa(e.Line) + ": " + message
+	}
+	return e.Path + ": " + message
+}
+
+// Unwrap ... obtain the underlying error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError ... create an error at a given position, keeping the position of an error that already has one
+func NewError(path string, line int, err error) *Error {
+	if positioned, ok := err.(*Error); ok {
+		return positioned
+	}
+	return &Error{Path: path, Line: line, Err: err}
+}
+
+// ErrorList ... the errors of all of the files of a project, in the order they were found
+type ErrorList []*Error
+
+// Add ... append an error at a given position, or each error of a list
+func (l *ErrorList) Add(path string, line int, err error) {
+	if list, ok := err.(ErrorList); ok {
+		*l = append(*l, list...)
+		return
+	}
+	*l = append(*l, NewError(path, line, err))
+}
+
+// Error ... describe the first error, along with how many more there are
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "No errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Err ... obtain the list as an error, or nil if it is empty
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
@@ -0,0 +1,110 @@
+/*
+ * Functions for checking the macros of a project, and how they and their variables are used
+ */
+
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// CheckMacroDocumentation ... compare the documented parameters of each macro against its signature
+func CheckMacroDocumentation(macros []Macro) []MacroIssue {
+
+	issues := make([]MacroIssue, 0)
+
+	for _, macro := range macros {
+
+		documented := make(map[string]bool)
+		for _, doc := range macro.DocumentedParams {
+			documented[strings.ToLower(doc.Name)] = true
+		}
+		actual := make(map[string]bool)
+		for _, param := range macro.Params {
+			actual[strings.ToLower(param.Name)] = true
+		}
+
+		for _, param := range macro.Params {
+			if !documented[strings.ToLower(param.Name)] {
+				issues = append(issues, MacroIssue{Macro: macro.Name, Filename: macro.Filename, LineNum: macro.LineNum, Param: param.Name,
+					Problem: "is not documented"})
+			}
+			if param.Keyword && param.Default == "" {
+				issues = append(issues, MacroIssue{Macro: macro.Name, Filename: macro.Filename, LineNum: macro.LineNum, Param: param.Name,
+					Problem: "has no default value"})
+			}
+		}
+
+		for _, doc := range macro.DocumentedParams {
+			if !actual[strings.ToLower(doc.Name)] {
+				issues = append(issues, MacroIssue{Macro: macro.Name, Filename: macro.Filename, LineNum: doc.LineNum, Param: doc.Name,
+					Problem: "is documented but does not exist"})
+			}
+		}
+	}
+
+	return issues
+}
+
+// UnusedMacros ... obtain the SAS macros that are defined but never called
+//
+// Only the calls of SAS macros are known, so the functions of other languages
+// are never reported.
+func UnusedMacros(macros []Macro, calls []MacroCall) []Macro {
+	called := make(map[string]bool)
+	for _, call := range calls {
+		called[strings.ToLower(call.Name)] = true
+	}
+	unused := make([]Macro, 0)
+	for _, macro := range macros {
+		if macro.Language != "" && macro.Language != LanguageSAS {
+			continue
+		}
+		if !called[strings.ToLower(macro.Name)] {
+			unused = append(unused, macro)
+		}
+	}
+	return unused
+}
+
+// UndefinedMacroCalls ... obtain the invocations of macros that are never defined
+func UndefinedMacroCalls(macros []Macro, calls []MacroCall) []MacroCall {
+	defined := make(map[string]bool)
+	for _, macro := range macros {
+		defined[strings.ToLower(macro.Name)] = true
+	}
+	undefined := make([]MacroCall, 0)
+	for _, call := range calls {
+		if !defined[strings.ToLower(call.Name)] {
+			undefined = append(undefined, call)
+		}
+	}
+	sort.SliceStable(undefined, func(i, j int) bool {
+		return strings.ToLower(undefined[i].Name) < strings.ToLower(undefined[j].Name)
+	})
+	return undefined
+}
+
+// GlobalMacroVariables ... obtain the assignments of the macro variables that are global to the project
+//
+// A variable is global when it is assigned outside of any macro, or declared
+// via |%global|, anywhere in the project.
+func GlobalMacroVariables(variables []MacroVariable) []MacroVariable {
+
+	global := make(map[string]bool)
+	for _, variable := range variables {
+		if variable.Macro == "" || variable.Statement == "%global" {
+			global[strings.ToLower(variable.Name)] = true
+		}
+	}
+
+	result := make([]MacroVariable, 0)
+	for _, variable := range variables {
+		if global[strings.ToLower(variable.Name)] && !variable.Local {
+			result = append(result, variable)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckMacroDocumentation(t *testing.T) {
+	macros := []Macro{{
+		Name:     "count",
+		Filename: "a.sas",
+		LineNum:  10,
+		Params:   []MacroParam{{Name: "ds"}, {Name: "out", Keyword: true}, {Name: "where", Default: "1", Keyword: true}},
+		DocumentedParams: []MacroParam{
+			{Name: "DS", LineNum: 7},
+			{Name: "where", LineNum: 8},
+			{Name: "stale", LineNum: 9},
+		},
+	}}
+	want := []MacroIssue{
+		{Macro: "count", Filename: "a.sas", LineNum: 10, Param: "out", Problem: "is not documented"},
+		{Macro: "count", Filename: "a.sas", LineNum: 10, Param: "out", Problem: "has no default value"},
+		{Macro: "count", Filename: "a.sas", LineNum: 9, Param: "stale", Problem: "is documented but does not exist"},
+	}
+	if got := CheckMacroDocumentation(macros); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckMacroDocumentation() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUnusedMacros(t *testing.T) {
+	macros := []Macro{{Name: "load"}, {Name: "Report"}, {Name: "unused"}, {Name: "helper", Language: LanguageR}}
+	calls := []MacroCall{{Name: "LOAD"}, {Name: "report"}}
+	got := make([]string, 0)
+	for _, macro := range UnusedMacros(macros, calls) {
+		got = append(got, macro.Name)
+	}
+	if want := []string{"unused"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UnusedMacros() = %q, want %q", got, want)
+	}
+}
+
+func TestUndefinedMacroCalls(t *testing.T) {
+	macros := []Macro{{Name: "Load"}}
+	calls := []MacroCall{{Name: "zeta"}, {Name: "load"}, {Name: "Alpha"}, {Name: "alpha"}}
+	got := make([]string, 0)
+	for _, call := range UndefinedMacroCalls(macros, calls) {
+		got = append(got, call.Name)
+	}
+	if want := []string{"Alpha", "alpha", "zeta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UndefinedMacroCalls() = %q, want %q", got, want)
+	}
+}
+
+func TestGlobalMacroVariables(t *testing.T) {
+	variables := []MacroVariable{
+		{Name: "root", Statement: "%let"},
+		{Name: "ROOT", Statement: "%let", Macro: "setup"},
+		{Name: "n", Statement: "%global", Macro: "setup"},
+		{Name: "n", Statement: "%let", Macro: "count"},
+		{Name: "n", Statement: "%let", Macro: "other", Local: true},
+		{Name: "i", Statement: "%let", Macro: "setup"},
+	}
+	got := make([]string, 0)
+	for _, variable := range GlobalMacroVariables(variables) {
+		got = append(got, variable.Name+" "+variable.Macro)
+	}
+	if want := []string{"root ", "ROOT setup", "n setup", "n count"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GlobalMacroVariables() = %q, want %q", got, want)
+	}
+}
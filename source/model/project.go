@@ -0,0 +1,74 @@
+/*
+ * Functions for aggregating the files of a project
+ */
+
+package model
+
+import "strings"
+
+// Add ... append everything found in another project, such as a single file, to this project
+//
+// The comments of each file are numbered in the order the files were read, so
+// those of the other project are numbered after the files already added.
+func (p *Project) Add(other Project) {
+
+	offset := 0
+	for _, cmt := range p.Comments {
+		if cmt.Index > offset {
+			offset = cmt.Index
+		}
+	}
+	for _, cmt := range other.Comments {
+		cmt.Index += offset
+		p.Comments = append(p.Comments, cmt)
+	}
+
+	p.Files = append(p.Files, other.Files...)
+	if p.Languages == nil {
+		p.Languages = make(map[string]string)
+	}
+	for path, language := range other.Languages {
+		p.Languages[path] = language
+	}
+	p.Includes = append(p.Includes, other.Includes...)
+	p.Macros = append(p.Macros, other.Macros...)
+	p.MacroCalls = append(p.MacroCalls, other.MacroCalls...)
+	p.Programs = append(p.Programs, other.Programs...)
+	p.Steps = append(p.Steps, other.Steps...)
+	p.Formats = append(p.Formats, other.Formats...)
+	p.MacroVariables = append(p.MacroVariables, other.MacroVariables...)
+	p.MacroVariableReferences = append(p.MacroVariableReferences, other.MacroVariableReferences...)
+	p.Variables = append(p.Variables, other.Variables...)
+	p.Datasets = append(p.Datasets, other.Datasets...)
+	p.Paths = append(p.Paths, other.Paths...)
+}
+
+// PartOf ... obtain the location of a part of a file, such as |report.ipynb › cell 3|, or the path itself if the part is blank
+func PartOf(path string, part string) string {
+	if part == "" {
+		return path
+	}
+	return path + " › " + part
+}
+
+// SplitPart ... obtain the path of a file and the name of a part of it from a location given by PartOf
+func SplitPart(location string) (string, string) {
+	if i := strings.Index(location, " › "); i != -1 {
+		return location[:i], location[i+len(" › "):]
+	}
+	return location, ""
+}
+
+// AppendUnique ... append strings to a list, skipping those already present
+func AppendUnique(list []string, strs ...string) []string {
+	for _, str := range strs {
+		present := str == ""
+		for _, item := range list {
+			present = present || item == str
+		}
+		if !present {
+			list = append(list, str)
+		}
+	}
+	return list
+}
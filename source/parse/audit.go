@@ -0,0 +1,45 @@
+/*
+ * Functions for finding the absolute paths hard-coded in live code
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// matches the start of a Windows drive letter, UNC or UNIX path of at least two levels
+var absolutePathRegex = regexp.MustCompile("(^|[\\s\"'=(,])([a-zA-Z]:[\\\\/]|\\\\\\\\[a-zA-Z0-9_.$-]+\\\\|~?/[a-zA-Z0-9_.$&-]+/)")
+
+// ParseStringForAbsolutePaths ... obtain the absolute paths given in code, with comments already stripped
+//
+// A path that starts just inside of quotes runs until the closing quote, so
+// that paths containing spaces are kept whole, otherwise it ends at the first
+// whitespace.
+func ParseStringForAbsolutePaths(code string) []model.Reference {
+
+	paths := make([]model.Reference, 0)
+
+	for i, line := range strings.Split(code, "\n") {
+		for _, sindex := range absolutePathRegex.FindAllStringSubmatchIndex(line, -1) {
+
+			start := sindex[4]
+			end := -1
+			if sindex[3] > sindex[2] && (line[sindex[2]] == '"' || line[sindex[2]] == '\'') {
+				end = strings.IndexByte(line[start:], line[sindex[2]])
+			} else {
+				end = strings.IndexAny(line[start:], " \t\r\"';),")
+			}
+			if end == -1 {
+				end = len(line) - start
+			}
+
+			paths = append(paths, model.Reference{Name: line[start : start+end], LineNum: i + 1})
+		}
+	}
+
+	return paths
+}
@@ -0,0 +1,37 @@
+package parse
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParseStringForAbsolutePaths(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{"unix", "libname raw '/data/raw study';\n", []string{"1 /data/raw study"}},
+		{"home", "cd ~/projects/trial\n", []string{"1 ~/projects/trial"}},
+		{"windows", "use \"C:\\Users\\me\\cohort.dta\", clear\nsave D:/out/final\n",
+			[]string{"1 C:\\Users\\me\\cohort.dta", "2 D:/out/final"}},
+		{"unc", "x=\\\\server\\share\\file.csv;\n", []string{"1 \\\\server\\share\\file.csv"}},
+		{"unterminated quote", "filename f \"/tmp/out.txt\n", []string{"1 /tmp/out.txt"}},
+		{"several on a line", "copy(/a/b, /c/d)\n", []string{"1 /a/b", "1 /c/d"}},
+		{"relative", "do code/clean.do\nuse data/x\n", []string{}},
+		{"single level", "cd /tmp\n", []string{}},
+		{"division", "x = a/b/c;\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, path := range ParseStringForAbsolutePaths(tt.code) {
+				got = append(got, strconv.Itoa(path.LineNum)+" "+path.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForAbsolutePaths() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+ * Functions for finding macro invocations and the macros that are never called
+ */
+
+package parse
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches a |%name(|, |%name;| or |%name | style macro invocation
+	macroCallRegex = regexp.MustCompile("%([a-zA-Z_][a-zA-Z0-9_]*)\\s*([(;]|\\s|$)")
+
+	// matches a |&name.| or |&name| style macro variable reference
+	macroVariableRegex = regexp.MustCompile("&+([a-zA-Z_][a-zA-Z0-9_]*)\\.?")
+
+	// macro statements, functions and SAS supplied autocall macros, along
+	// with their abbreviations and DBCS versions, which are never
+	// user-defined macros
+	builtinMacros = map[string]bool{
+		"abort": true, "bquote": true, "by": true, "cmpres": true, "compstor": true,
+		"copy": true, "datatyp": true, "display": true, "do": true, "else": true,
+		"end": true, "eval": true, "global": true, "goto": true, "if": true,
+		"inc": true, "include": true, "index": true, "input": true,
+		"kcmpres": true, "kindex": true, "kleft": true, "klength": true,
+		"klowcase": true, "kqcmpres": true, "kqleft": true, "kqlowcase": true,
+		"kqscan": true, "kqsubstr": true, "kqtrim": true, "kqupcase": true,
+		"kscan": true, "ksubstr": true, "ktrim": true, "kupcase": true,
+		"kverify": true, "label": true, "left": true, "length": true,
+		"let": true, "list": true, "local": true, "lowcase": true, "macro": true, "mend": true,
+		"nrbquote": true, "nrquote": true, "nrstr": true, "put": true,
+		"qcmpres": true, "qleft": true, "qlowcase": true, "qscan": true,
+		"qsubstr": true, "qsysfunc": true, "qtrim": true, "qupcase": true,
+		"quote": true, "return": true, "run": true, "scan": true, "str": true,
+		"substr": true, "superq": true, "symdel": true, "symexist": true,
+		"symglobl": true, "symlocal": true, "syscall": true, "sysevalf": true,
+		"sysexec": true, "sysfunc": true, "sysget": true, "sysmacdelete": true,
+		"sysmacexec": true, "sysmacexist": true, "sysmexecdepth": true,
+		"sysmexecname": true, "sysprod": true, "sysrput": true, "syslput": true,
+		"then": true, "to": true, "trim": true, "unquote": true, "until": true,
+		"upcase": true, "verify": true, "while": true, "window": true,
+	}
+)
+
+// ParseStringForMacroCalls ... obtain all of the macro invocations from a given string
+func ParseStringForMacroCalls(filename string, contents string, macros []model.Macro) []model.MacroCall {
+
+	calls := make([]model.MacroCall, 0)
+	code := StripSASComments(contents)
+
+	for _, sindex := range macroCallRegex.FindAllStringSubmatchIndex(code, -1) {
+
+		name := code[sindex[2]:sindex[3]]
+		if builtinMacros[strings.ToLower(name)] {
+			continue
+		}
+
+		// skip the name of the macro in a |%macro name| statement
+		if sindex[0] > 0 && strings.HasSuffix(strings.ToLower(strings.TrimRight(code[:sindex[0]], " \t\r\n")), "%macro") {
+			continue
+		}
+
+		call := model.MacroCall{
+			Name:     name,
+			Filename: filename,
+			LineNum:  LineNumberAt(code, sindex[0]),
+		}
+
+		// attribute the call to the macro it appears inside of, if any
+		for _, macro := range macros {
+			if macro.Filename == filename && macro.LineNum <= call.LineNum && call.LineNum <= macro.EndLineNum {
+				call.Caller = macro.Name
+			}
+		}
+
+		calls = append(calls, call)
+	}
+
+	return calls
+}
+
+// includeCandidates ... obtain the paths that an included path may refer to, in the order they are looked for
+//
+// Macro variable references cannot be evaluated, so they are removed and the
+// remaining path is looked for as given, and then relative to the including
+// file unless it is absolute.
+func includeCandidates(includingFile string, rawPath string) []string {
+
+	path := macroVariableRegex.ReplaceAllString(rawPath, "")
+	path = strings.Replace(path, "\\", "/", -1)
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil
+	}
+	if filepath.IsAbs(path) {
+		return []string{path}
+	}
+
+	// a part of a file, such as the node of a project, is found next to the file
+	includingFile, _ = model.SplitPart(includingFile)
+
+	return []string{path, filepath.Join(filepath.Dir(includingFile), path)}
+}
+
+// ResolveIncludePath ... attempt to locate the file referred to by an included path
+//
+// The path is looked for as given by includeCandidates, and finally by its
+// filename in the code directory.
+func ResolveIncludePath(codeDir string, includingFile string, rawPath string) (string, bool) {
+
+	candidates := includeCandidates(includingFile, rawPath)
+	if len(candidates) < 1 {
+		return "", false
+	}
+	if !filepath.IsAbs(candidates[0]) {
+		candidates = append(candidates, filepath.Join(codeDir, filepath.Base(candidates[0])))
+	}
+
+	for _, candidate := range candidates {
+		info, err := os.Stat(candidate)
+		if err == nil && !info.IsDir() {
+			return filepath.Clean(candidate), true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,92 @@
+package parse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestParseStringForMacroCalls(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"open code", "%load(visits);\n%report;\n", []string{"load  1", "report  2"}},
+		{"inside a macro", "%macro outer;\n  %inner(x=1)\n%mend;\n", []string{"inner outer 2"}},
+		{"macro statements", "%let x = %sysfunc(today());\n%if &x %then %do; %put &x; %end;\n", []string{}},
+		{"include abbreviated", "%inc \"setup.sas\";\n", []string{}},
+		{"DBCS functions", "%let y = %ksubstr(&x, 1, 2) %klength(&x) %kscan(&x, 1);\n", []string{}},
+		{"macro name", "%macro load(ds);\n%mend load;\n", []string{}},
+		{"commented out", "/* %load(visits); */\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			macros := ParseStringForMacros("a.sas", tt.contents)
+			got := make([]string, 0)
+			for _, call := range ParseStringForMacroCalls("a.sas", tt.contents, macros) {
+				got = append(got, call.Name+" "+call.Caller+" "+strconv.Itoa(call.LineNum))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForMacroCalls() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUndefinedMacroCallsSkipsBuiltins(t *testing.T) {
+	contents := "%macro load;\n%mend;\n%load;\n%inc 'x.sas';\n%ksubstr(&x, 1);\n%Zeta;\n%alpha(1);\n"
+	macros := ParseStringForMacros("a.sas", contents)
+	got := make([]string, 0)
+	for _, call := range model.UndefinedMacroCalls(macros, ParseStringForMacroCalls("a.sas", contents, macros)) {
+		got = append(got, call.Name)
+	}
+	if want := []string{"alpha", "Zeta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("UndefinedMacroCalls() = %q, want %q", got, want)
+	}
+}
+
+func TestResolveIncludePath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gommentary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "lib"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"setup.sas", filepath.Join("lib", "macros.sas")} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("%macro m; %mend;"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	including := filepath.Join(dir, "main.sas")
+
+	tests := []struct {
+		name      string
+		path      string
+		want      string
+		wantFound bool
+	}{
+		{"relative to the including file", "lib/macros.sas", filepath.Join(dir, "lib", "macros.sas"), true},
+		{"macro variables removed", "&root.lib\\macros.sas", filepath.Join(dir, "lib", "macros.sas"), true},
+		{"absolute path only as given", "/elsewhere/setup.sas", "", false},
+		{"elsewhere by name", "other/setup.sas", filepath.Join(dir, "setup.sas"), true},
+		{"missing", "missing.sas", "", false},
+		{"only macro variables", "&path", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := ResolveIncludePath(dir, including, tt.path)
+			if got != tt.want || found != tt.wantFound {
+				t.Errorf("ResolveIncludePath() = %q, %v, want %q, %v", got, found, tt.want, tt.wantFound)
+			}
+		})
+	}
+}
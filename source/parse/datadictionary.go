@@ -0,0 +1,84 @@
+/*
+ * Functions for assembling the data dictionary from the @var comments
+ */
+
+package parse
+
+import (
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// VariablesFromComments ... obtain the data dictionary entries from the |@var name :attribute value| comments
+//
+// Variables not given a |:dataset| belong to the step of the lineage that
+// the comment appears inside of, or failing that the next step of the file.
+func VariablesFromComments(comments []model.Comment, steps []model.DatasetStep) []model.Variable {
+
+	variables := make([]model.Variable, 0)
+
+	for _, cmt := range comments {
+
+		if strings.Trim(strings.TrimSpace(cmt.Keyword), "@") != "var" {
+			continue
+		}
+		fields := strings.Fields(cmt.Text)
+		if len(fields) < 1 {
+			continue
+		}
+
+		attributes, description := model.ParseAttributes(strings.TrimSpace(cmt.Text[strings.Index(cmt.Text, fields[0])+len(fields[0]):]))
+		variable := model.Variable{
+			Name:        fields[0],
+			Description: description,
+			Filename:    cmt.Filename,
+			LineNum:     cmt.LineNum,
+		}
+
+		for _, attribute := range attributes {
+			switch attribute.Name {
+			case "dataset":
+				variable.Dataset = attribute.Value
+			case "type":
+				variable.Type = strings.ToLower(attribute.Value)
+			case "label":
+				variable.Label = attribute.Value
+			case "codes":
+				variable.Codes = attribute.Value
+			default:
+				variable.Attributes = append(variable.Attributes, attribute)
+			}
+		}
+
+		if variable.Dataset == "" {
+			variable.Dataset = stepDataset(cmt.Filename, cmt.LineNum, steps)
+		}
+
+		variables = append(variables, variable)
+	}
+
+	return variables
+}
+
+// stepDataset ... obtain the dataset written by the step containing, or following, a given line of a file
+func stepDataset(filename string, lineNum int, steps []model.DatasetStep) string {
+
+	var next *model.DatasetStep
+	for i, step := range steps {
+		if step.Filename != filename || len(step.Outputs) < 1 {
+			continue
+		}
+		if step.LineNum <= lineNum && lineNum <= step.EndLineNum {
+			return step.Outputs[0]
+		}
+		if step.LineNum > lineNum && (next == nil || step.LineNum < next.LineNum) {
+			next = &steps[i]
+		}
+	}
+
+	if next == nil {
+		return ""
+	}
+	return next.Outputs[0]
+}
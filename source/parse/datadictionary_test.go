@@ -0,0 +1,35 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestVariablesFromComments(t *testing.T) {
+	comments := []model.Comment{
+		{Keyword: "@var ", Text: "age :type Numeric :label \"Age at visit\" :units years Age in whole years", Filename: "a.sas", LineNum: 2},
+		{Keyword: "@var ", Text: "sex :dataset raw.registry :codes '1=Male 2=Female'", Filename: "a.sas", LineNum: 3},
+		{Keyword: "@var ", Text: "flag Set later", Filename: "a.sas", LineNum: 20},
+		{Keyword: "@var ", Text: "orphan", Filename: "b.sas", LineNum: 1},
+		{Keyword: "@var ", Text: " ", Filename: "a.sas", LineNum: 4},
+		{Keyword: "@note ", Text: "not a variable", Filename: "a.sas", LineNum: 5},
+	}
+	steps := []model.DatasetStep{
+		{Filename: "a.sas", LineNum: 1, EndLineNum: 10, Step: "data", Outputs: []string{"cohort"}},
+		{Filename: "a.sas", LineNum: 40, EndLineNum: 45, Step: "data", Outputs: []string{"late"}},
+		{Filename: "a.sas", LineNum: 30, EndLineNum: 35, Step: "data", Outputs: []string{"final"}},
+		{Filename: "a.sas", LineNum: 25, EndLineNum: 26, Step: "proc print", Inputs: []string{"cohort"}},
+	}
+	want := []model.Variable{
+		{Name: "age", Dataset: "cohort", Type: "numeric", Label: "Age at visit", Description: "Age in whole years",
+			Attributes: []model.Attribute{{Name: "units", Value: "years"}}, Filename: "a.sas", LineNum: 2},
+		{Name: "sex", Dataset: "raw.registry", Codes: "1=Male 2=Female", Filename: "a.sas", LineNum: 3},
+		{Name: "flag", Dataset: "final", Description: "Set later", Filename: "a.sas", LineNum: 20},
+		{Name: "orphan", Filename: "b.sas", LineNum: 1},
+	}
+	if got := VariablesFromComments(comments, steps); !reflect.DeepEqual(got, want) {
+		t.Errorf("VariablesFromComments() = %+v, want %+v", got, want)
+	}
+}
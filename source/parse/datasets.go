@@ -0,0 +1,63 @@
+/*
+ * Functions for reading the datasets of the data directory
+ */
+
+package parse
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// ReadDatasetsFromDirectory ... obtain the variable metadata of every dataset in a given directory
+//
+// A dataset that cannot be read does not stop the others from being read;
+// the errors of all such datasets are returned together as a |model.ErrorList|.
+func ReadDatasetsFromDirectory(dataDir string) ([]model.Dataset, error) {
+
+	datasets := make([]model.Dataset, 0)
+	errs := make(model.ErrorList, 0)
+
+	dataDirContents, err := ioutil.ReadDir(dataDir)
+	if err != nil {
+		return datasets, err
+	}
+
+	for _, file := range dataDirContents {
+
+		if file.IsDir() {
+			continue
+		}
+		path := filepath.Join(dataDir, file.Name())
+
+		extension := strings.ToLower(filepath.Ext(file.Name()))
+		if extension != ".dta" && extension != ".sas7bdat" {
+			continue
+		}
+		dataset, err := readDatasetFile(path)
+		if err != nil {
+			errs.Add(path, 0, err)
+			continue
+		}
+		datasets = append(datasets, dataset)
+	}
+
+	return datasets, errs.Err()
+}
+
+// readDatasetFile ... read a single dataset, turning any panic of its reader into an error of that file
+//
+// A reader that trips over a file it does not expect must not take the
+// other datasets of the directory down with it.
+func readDatasetFile(path string) (dataset model.Dataset, err error) {
+
+	defer recoverParser(path, &err)
+
+	if strings.ToLower(filepath.Ext(path)) == ".dta" {
+		return ReadDtaFile(path)
+	}
+	return ReadSas7bdatFile(path)
+}
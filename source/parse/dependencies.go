@@ -0,0 +1,296 @@
+/*
+ * Functions for reading the scripts, macro libraries and commands a file depends on
+ */
+
+package parse
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches the |options sasautos=| autocall path option
+	sasautosRegex = regexp.MustCompile("(?i)\\bsasautos\\s*=\\s*(\\([^)]*\\)|\"[^\"]*\"|'[^']*'|[^\\s]+)")
+
+	// matches the |filename ref "path"| and |libname lib engine "path"| statements
+	referenceRegex = regexp.MustCompile("(?is)^(filename|libname)\\s+([a-zA-Z_][a-zA-Z0-9_]*)\\s+(?:([a-zA-Z0-9_]+)\\s+)?(\"[^\"]*\"|'[^']*')")
+
+	// matches the SAS |x|, |systask command| and |%sysexec| statements
+	sasCommandRegex = regexp.MustCompile("(?is)^(x|systask\\s+command|%sysexec)\\s+(.+)$")
+
+	// matches the SAS |call system()| routine
+	callSystemRegex = regexp.MustCompile("(?is)\\bcall\\s+system\\s*\\((.*)\\)")
+
+	// matches a single or double quoted string
+	quotedStringRegex = regexp.MustCompile("\"[^\"]*\"|'[^']*'")
+
+	// matches the Stata |shell|, |!| and |winexec| commands
+	stataCommandRegex = regexp.MustCompile("(?m)^[ \\t]*(?:(?:capture|capt?|quietly|qui|noisily|noi)[ \\t]*:?[ \\t]+)*((?:shell|sh|winexec)[ \\t]|!)[ \\t]*([^\\n]+)$")
+
+	// matches the Stata |do|, |run|, |include| and |adopath| commands, along with any prefixes
+	stataDependencyRegex = regexp.MustCompile("(?m)^[ \\t]*(?:(?:capture|capt?|quietly|qui|noisily|noi)[ \\t]*:?[ \\t]+)*(do|run|include|adopath)[ \\t]+([^\\n]+)$")
+
+	// matches the Stata |local name value| and |global name value| definitions
+	stataMacroDefinitionRegex = regexp.MustCompile("(?m)^[ \\t]*(?:(?:capture|capt?|quietly|qui)[ \\t]*:?[ \\t]+)?(loc|loca|local|gl|glo|glob|globa|global)[ \\t]+([a-zA-Z_][a-zA-Z0-9_]*)[ \\t]*([^\\n]*)$")
+
+	// matches the |`name'| local macro and |$name| or |${name}| global macro references
+	stataLocalReferenceRegex  = regexp.MustCompile("`([a-zA-Z_][a-zA-Z0-9_]*)'")
+	stataGlobalReferenceRegex = regexp.MustCompile("\\$\\{?([a-zA-Z_][a-zA-Z0-9_]*)\\}?")
+)
+
+// ParseStringForStataDependencies ... obtain the scripts and ado paths a Stata string depends on
+func ParseStringForStataDependencies(contents string) []model.Dependency {
+
+	includes := make([]model.Dependency, 0)
+	code := StripStataComments(contents)
+
+	macros := newStataMacros(code)
+
+	for _, sindex := range stataDependencyRegex.FindAllStringSubmatchIndex(code, -1) {
+
+		macros.DefineUntil(sindex[0])
+
+		command := code[sindex[2]:sindex[3]]
+		arguments := strings.TrimSpace(code[sindex[4]:sindex[5]])
+
+		// |adopath + dir| and |adopath ++ dir| add to the ado path
+		if command == "adopath" {
+			if !strings.HasPrefix(arguments, "+") {
+				continue
+			}
+			arguments = strings.TrimSpace(strings.TrimLeft(arguments, "+"))
+		}
+
+		argument, _ := firstStataArgument(arguments)
+		path := macros.Resolve(argument)
+		if path == "" {
+			continue
+		}
+
+		// Stata assumes the .do extension when none is given
+		if command != "adopath" && filepath.Ext(path) == "" {
+			path += ".do"
+		}
+
+		kind := model.DependencyScript
+		switch {
+		case command == "adopath":
+			kind = model.DependencyAutocall
+		case strings.HasSuffix(strings.ToLower(path), ".ado"):
+			kind = model.DependencyMacro
+		}
+
+		includes = append(includes, model.Dependency{
+			LineNum:   LineNumberAt(code, sindex[0]),
+			Path:      path,
+			Statement: command,
+			Kind:      kind,
+		})
+	}
+
+	// handle the |shell|, |!| and |winexec| external commands
+	for _, sindex := range stataCommandRegex.FindAllStringSubmatchIndex(code, -1) {
+		includes = append(includes, model.Dependency{
+			LineNum:   LineNumberAt(code, sindex[0]),
+			Path:      strings.TrimSpace(code[sindex[4]:sindex[5]]),
+			Statement: strings.TrimSpace(code[sindex[2]:sindex[3]]),
+			Kind:      model.DependencyCommand,
+		})
+	}
+
+	return includes
+}
+
+// ParseStringForSASDependencies ... obtain the autocall paths, references and external commands of a SAS string
+func ParseStringForSASDependencies(contents string) []model.Dependency {
+
+	dependencies := make([]model.Dependency, 0)
+	code := StripSASComments(contents)
+
+	for _, statement := range SplitSASStatements(code) {
+
+		text := strings.TrimSpace(statement.Text)
+		lowercase := strings.ToLower(text)
+		lineNum := LineNumberAt(code, statement.Offset)
+
+		// handle the |options sasautos=(...)| autocall paths
+		if strings.HasPrefix(lowercase, "options ") || strings.HasPrefix(lowercase, "option ") {
+			for _, match := range sasautosRegex.FindAllStringSubmatch(text, -1) {
+				for _, path := range sasautosPaths(match[1]) {
+					dependencies = append(dependencies, model.Dependency{
+						LineNum:   lineNum,
+						Path:      path,
+						Statement: "options sasautos",
+						Kind:      model.DependencyAutocall,
+					})
+				}
+			}
+			continue
+		}
+
+		// handle the |filename| and |libname| references, noting that a
+		// piped fileref is really an external command
+		if match := referenceRegex.FindStringSubmatch(text); match != nil {
+			dependency := model.Dependency{
+				LineNum:   lineNum,
+				Path:      match[4][1 : len(match[4])-1],
+				Statement: strings.ToLower(match[1]),
+				Kind:      model.DependencyReference,
+				Name:      match[2],
+			}
+			if strings.EqualFold(match[3], "pipe") {
+				dependency.Statement += " pipe"
+				dependency.Kind = model.DependencyCommand
+			}
+			dependencies = append(dependencies, dependency)
+			continue
+		}
+
+		// handle the |x|, |systask command| and |%sysexec| external commands
+		if match := sasCommandRegex.FindStringSubmatch(text); match != nil && !strings.HasPrefix(match[2], "=") {
+			dependencies = append(dependencies, model.Dependency{
+				LineNum:   lineNum,
+				Path:      strings.Join(strings.Fields(match[2]), " "),
+				Statement: strings.ToLower(strings.Join(strings.Fields(match[1]), " ")),
+				Kind:      model.DependencyCommand,
+			})
+			continue
+		}
+
+		// handle the |call system()| routine of a data step
+		if match := callSystemRegex.FindStringSubmatch(text); match != nil {
+			dependencies = append(dependencies, model.Dependency{
+				LineNum:   lineNum,
+				Path:      strings.Join(strings.Fields(match[1]), " "),
+				Statement: "call system",
+				Kind:      model.DependencyCommand,
+			})
+		}
+	}
+
+	return dependencies
+}
+
+// sasautosPaths ... obtain the paths or filerefs given to the sasautos option
+func sasautosPaths(value string) []string {
+	paths := make([]string, 0)
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "("), ")")
+	for _, quoted := range quotedStringRegex.FindAllString(value, -1) {
+		paths = append(paths, quoted[1:len(quoted)-1])
+	}
+	for _, word := range strings.Fields(quotedStringRegex.ReplaceAllString(value, " ")) {
+		word = strings.Trim(word, ",")
+		// the default autocall library is not a dependency of the project
+		if word != "" && !strings.EqualFold(word, "sasautos") {
+			paths = append(paths, word)
+		}
+	}
+	return paths
+}
+
+// firstStataArgument ... obtain the first, possibly quoted, argument of a Stata command, along with the number of bytes it spans
+//
+// The span covers any quotes around the argument, as well as the spaces
+// before it. A quote that is never closed is read as part of the argument.
+func firstStataArgument(arguments string) (string, int) {
+	trimmed := strings.TrimLeft(arguments, " \t\r\n")
+	offset := len(arguments) - len(trimmed)
+	switch {
+	case strings.HasPrefix(trimmed, "`\""):
+		if end := strings.Index(trimmed, "\"'"); end != -1 {
+			return trimmed[2:end], offset + end + 2
+		}
+	case strings.HasPrefix(trimmed, "\""):
+		if end := strings.Index(trimmed[1:], "\""); end != -1 {
+			return trimmed[1 : end+1], offset + end + 2
+		}
+	}
+	fields := strings.Fields(trimmed)
+	if len(fields) < 1 {
+		return "", len(arguments)
+	}
+	argument := strings.TrimSuffix(fields[0], ",")
+	return argument, offset + len(argument)
+}
+
+// unquoteStataString ... remove the double quotes or compound double quotes surrounding a string
+func unquoteStataString(str string) string {
+	if strings.HasPrefix(str, "`\"") && strings.HasSuffix(str, "\"'") && len(str) >= 4 {
+		return str[2 : len(str)-2]
+	}
+	if strings.HasPrefix(str, "\"") && strings.HasSuffix(str, "\"") && len(str) >= 2 {
+		return str[1 : len(str)-1]
+	}
+	return str
+}
+
+// stataMacros object definition, which tracks the local and global macros of a Stata string
+type stataMacros struct {
+
+	// Stata code, with comments already stripped
+	code string
+
+	// indices of the macro definitions not yet made
+	definitions [][]int
+
+	// values of the macros defined so far
+	locals  map[string]string
+	globals map[string]string
+}
+
+// newStataMacros ... obtain the macro definitions of the given Stata code
+func newStataMacros(code string) *stataMacros {
+	return &stataMacros{
+		code:        code,
+		definitions: stataMacroDefinitionRegex.FindAllStringSubmatchIndex(code, -1),
+		locals:      make(map[string]string),
+		globals:     make(map[string]string),
+	}
+}
+
+// DefineUntil ... make the macro definitions preceding the given offset into the code
+//
+// This way only the definitions made before a command are used to resolve it.
+func (m *stataMacros) DefineUntil(offset int) {
+	for len(m.definitions) > 0 && m.definitions[0][0] < offset {
+		def := m.definitions[0]
+		m.definitions = m.definitions[1:]
+		name := m.code[def[4]:def[5]]
+		value := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(m.code[def[6]:def[7]]), "="))
+		value = m.Resolve(unquoteStataString(value))
+		if strings.HasPrefix(m.code[def[2]:def[3]], "l") {
+			m.locals[name] = value
+		} else {
+			m.globals[name] = value
+		}
+	}
+}
+
+// Resolve ... substitute the macros defined so far into a given string
+func (m *stataMacros) Resolve(str string) string {
+	return ResolveStataMacros(str, m.locals, m.globals)
+}
+
+// ResolveStataMacros ... substitute the known local and global macros into a given string
+//
+// References to unknown macros are left as-is.
+func ResolveStataMacros(str string, locals map[string]string, globals map[string]string) string {
+	str = stataLocalReferenceRegex.ReplaceAllStringFunc(str, func(ref string) string {
+		if value, ok := locals[ref[1:len(ref)-1]]; ok {
+			return value
+		}
+		return ref
+	})
+	str = stataGlobalReferenceRegex.ReplaceAllStringFunc(str, func(ref string) string {
+		name := strings.Trim(ref, "${}")
+		if value, ok := globals[name]; ok {
+			return value
+		}
+		return ref
+	})
+	return str
+}
@@ -0,0 +1,89 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStringForStataDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"do and run", "do clean\nrun \"lib/setup.do\"\n", []string{"script do clean.do", "script run lib/setup.do"}},
+		{"prefixes", "capture noisily: do a.do\nqui include b\n", []string{"script do a.do", "script include b.do"}},
+		{"ado file", "run helpers.ado\n", []string{"macro run helpers.ado"}},
+		{"adopath", "adopath + \"ado/personal\"\nadopath ++ ado/site\nadopath - old\n",
+			[]string{"autocall adopath ado/personal", "autocall adopath ado/site"}},
+		{"macros", "global root \"/proj\"\nlocal sub `\"$root/code\"'\ndo `sub'/a\nlocal sub other\n",
+			[]string{"script do /proj/code/a.do"}},
+		{"unknown macro", "do `dir'/a.do\n", []string{"script do `dir'/a.do"}},
+		{"commands", "shell rm tmp.csv\n!gzip out.csv\nwinexec notepad\n",
+			[]string{"command shell rm tmp.csv", "command ! gzip out.csv", "command winexec notepad"}},
+		{"commented out", "* do old\n// run older\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, dependency := range ParseStringForStataDependencies(tt.contents) {
+				got = append(got, dependency.Kind+" "+dependency.Statement+" "+dependency.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForStataDependencies() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveStataMacros(t *testing.T) {
+	locals := map[string]string{"file": "a.do"}
+	globals := map[string]string{"root": "/proj"}
+	tests := []struct {
+		name string
+		str  string
+		want string
+	}{
+		{"local", "`file'", "a.do"},
+		{"global", "$root/x", "/proj/x"},
+		{"braced global", "${root}x", "/projx"},
+		{"unknown", "`other' $other", "`other' $other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ResolveStataMacros(tt.str, locals, globals); got != tt.want {
+				t.Errorf("ResolveStataMacros() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringForSASDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"sasautos", "options mprint sasautos=(\"/lib/macros\" mylib sasautos);\n",
+			[]string{"autocall options sasautos /lib/macros", "autocall options sasautos mylib"}},
+		{"references", "libname raw base '/data/raw';\nfilename cfg \"config.txt\";\n",
+			[]string{"reference libname /data/raw", "reference filename config.txt"}},
+		{"piped fileref", "filename ls pipe 'ls -l';\n", []string{"command filename pipe ls -l"}},
+		{"commands", "x 'rm tmp.csv';\nsystask  command \"gzip out.csv\";\n%sysexec cp a b;\n",
+			[]string{"command x 'rm tmp.csv'", "command systask command \"gzip out.csv\"", "command %sysexec cp a b"}},
+		{"call system", "data _null_; call system('ls'); run;\n", []string{"command call system 'ls'"}},
+		{"assignment to x", "data a; x = 1; run;\n", []string{}},
+		{"commented out", "/* x 'rm -rf /'; */\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, dependency := range ParseStringForSASDependencies(tt.contents) {
+				got = append(got, dependency.Kind+" "+dependency.Statement+" "+dependency.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForSASDependencies() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+/*
+ * Functions for reading an external data dictionary and reconciling it with the @var comments
+ */
+
+package parse
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/fileutils"
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// headings of the data dictionary CSV columns, along with the Variable field each is read into
+var dictionaryColumns = map[string]string{
+	"name": "name", "variable": "name", "var": "name", "dataset": "dataset", "table": "dataset",
+	"type": "type", "label": "label", "description": "description", "codes": "codes", "code": "codes",
+	"values": "codes",
+}
+
+// ReadDictionaryFile ... obtain the variables listed in a data dictionary CSV
+//
+// The first record is taken to be the headings, of which a name column is
+// required, and lines starting with # are ignored.
+func ReadDictionaryFile(path string) ([]model.Variable, error) {
+
+	records, err := fileutils.ReadFileIntoStringArray(path)
+	var csvErr *csv.ParseError
+	if errors.As(err, &csvErr) {
+		return nil, model.NewError(path, csvErr.StartLine, csvErr.Err)
+	} else if err != nil {
+		return nil, err
+	}
+
+	// determine which field each column is read into
+	columns := make([]string, len(records[0]))
+	hasName := false
+	for i, heading := range records[0] {
+		columns[i] = dictionaryColumns[strings.ToLower(strings.TrimSpace(heading))]
+		if columns[i] == "name" {
+			hasName = true
+		}
+	}
+	if !hasName {
+		return nil, model.NewError(path, 1, fmt.Errorf("Data dictionary has no name or variable column."))
+	}
+
+	variables := make([]model.Variable, 0, len(records)-1)
+	for _, record := range records[1:] {
+
+		variable := model.Variable{Dictionary: path}
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			switch columns[i] {
+			case "name":
+				variable.Name = value
+			case "dataset":
+				variable.Dataset = value
+			case "type":
+				variable.Type = strings.ToLower(value)
+			case "label":
+				variable.Label = value
+			case "description":
+				variable.Description = value
+			case "codes":
+				variable.Codes = value
+			}
+		}
+
+		if variable.Name != "" {
+			variables = append(variables, variable)
+		}
+	}
+
+	return variables, nil
+}
+
+// sameVariable ... whether two entries describe the same variable; a blank dataset matches any
+func sameVariable(a model.Variable, b model.Variable) bool {
+	if !strings.EqualFold(a.Name, b.Name) {
+		return false
+	}
+	return a.Dataset == "" || b.Dataset == "" || strings.EqualFold(a.Dataset, b.Dataset)
+}
+
+// MergeDictionary ... combine the variables documented in the code with those of a data dictionary
+//
+// Details given in the code take precedence, with the dictionary filling in
+// any that were left out. Variables only found in the dictionary are kept,
+// and have no filename.
+func MergeDictionary(documented []model.Variable, dictionary []model.Variable) []model.Variable {
+
+	merged := append([]model.Variable{}, documented...)
+	used := make([]bool, len(dictionary))
+
+	for i := range merged {
+		for j, entry := range dictionary {
+			if used[j] || !sameVariable(merged[i], entry) {
+				continue
+			}
+			used[j] = true
+
+			merged[i].Dictionary = entry.Dictionary
+			if merged[i].Dataset == "" {
+				merged[i].Dataset = entry.Dataset
+			}
+			if merged[i].Type == "" {
+				merged[i].Type = entry.Type
+			}
+			if merged[i].Label == "" {
+				merged[i].Label = entry.Label
+			}
+			if merged[i].Description == "" {
+				merged[i].Description = entry.Description
+			}
+			if merged[i].Codes == "" {
+				merged[i].Codes = entry.Codes
+			}
+			if entry.Type != "" && !strings.EqualFold(entry.Type, merged[i].Type) {
+				merged[i].Attributes = append(merged[i].Attributes, model.Attribute{Name: "dictionary type", Value: entry.Type})
+			}
+			break
+		}
+	}
+
+	for j, entry := range dictionary {
+		if !used[j] {
+			merged = append(merged, entry)
+		}
+	}
+
+	return merged
+}
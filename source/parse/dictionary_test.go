@@ -0,0 +1,81 @@
+package parse
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestReadDictionaryFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gommentary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name     string
+		contents string
+		want     []model.Variable
+		wantLine int
+	}{
+		{"columns", "# exported from the registry\nVariable,Table,Type,Label,Notes,Values\nage, cohort ,NUM,Age,ignored,\n,cohort,num,,,\nsex,cohort,char,Sex,,1=M 2=F\n",
+			[]model.Variable{
+				{Name: "age", Dataset: "cohort", Type: "num", Label: "Age"},
+				{Name: "sex", Dataset: "cohort", Type: "char", Label: "Sex", Codes: "1=M 2=F"},
+			}, 0},
+		{"no name column", "label,type\nAge,num\n", nil, 1},
+		{"malformed", "name,label\nage,\"Age\nsex,Sex\n", nil, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, "dictionary.csv")
+			if err := ioutil.WriteFile(path, []byte(tt.contents), 0644); err != nil {
+				t.Fatal(err)
+			}
+			for i := range tt.want {
+				tt.want[i].Dictionary = path
+			}
+
+			got, err := ReadDictionaryFile(path)
+			var positioned *model.Error
+			if tt.wantLine > 0 && (!errors.As(err, &positioned) || positioned.Line != tt.wantLine) {
+				t.Errorf("ReadDictionaryFile() error = %v, want an error at line %d", err, tt.wantLine)
+			}
+			if tt.wantLine == 0 && err != nil {
+				t.Errorf("ReadDictionaryFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDictionaryFile() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeDictionary(t *testing.T) {
+	documented := []model.Variable{
+		{Name: "age", Type: "numeric", Description: "Age in years", Filename: "a.sas", LineNum: 2},
+		{Name: "sex", Dataset: "cohort", Filename: "a.sas", LineNum: 3},
+		{Name: "flag", Filename: "a.sas", LineNum: 4},
+	}
+	dictionary := []model.Variable{
+		{Name: "AGE", Dataset: "cohort", Type: "char", Label: "Age", Description: "Ignored", Dictionary: "d.csv"},
+		{Name: "sex", Dataset: "visits", Label: "Sex at visit", Dictionary: "d.csv"},
+		{Name: "sex", Dataset: "cohort", Type: "char", Codes: "1=M 2=F", Dictionary: "d.csv"},
+	}
+	want := []model.Variable{
+		{Name: "age", Dataset: "cohort", Type: "numeric", Label: "Age", Description: "Age in years",
+			Attributes: []model.Attribute{{Name: "dictionary type", Value: "char"}}, Filename: "a.sas", LineNum: 2, Dictionary: "d.csv"},
+		{Name: "sex", Dataset: "cohort", Type: "char", Codes: "1=M 2=F", Filename: "a.sas", LineNum: 3, Dictionary: "d.csv"},
+		{Name: "flag", Filename: "a.sas", LineNum: 4},
+		{Name: "sex", Dataset: "visits", Label: "Sex at visit", Dictionary: "d.csv"},
+	}
+	if got := MergeDictionary(documented, dictionary); !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeDictionary() = %+v, want %+v", got, want)
+	}
+}
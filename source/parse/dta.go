@@ -0,0 +1,417 @@
+/*
+ * Functions for reading the variable metadata of Stata .dta files, versions 114 to 119
+ */
+
+package parse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// dtaReader ... reader of the fields of a .dta file, remembering the first error encountered
+type dtaReader struct {
+
+	// file being read
+	r io.ReadSeeker
+
+	// byte order of the numbers in the file
+	order binary.ByteOrder
+
+	// whether strings are latin-1 rather than utf-8, i.e. a version before 118
+	latin1 bool
+
+	// size of the file, which no count or length read from it may exceed
+	size int64
+
+	// first error encountered, after which nothing more is read
+	err error
+}
+
+// bytes ... read the next n bytes of the file, failing rather than allocating more than is left of it
+func (d *dtaReader) bytes(n int) []byte {
+	if d.err != nil || n < 0 {
+		return nil
+	}
+	left := d.remaining()
+	switch {
+	case d.err != nil:
+		return nil
+	case int64(n) > left && left == 0:
+		d.err = io.EOF
+		return nil
+	case int64(n) > left:
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := make([]byte, n)
+	_, d.err = io.ReadFull(d.r, b)
+	return b
+}
+
+// uint ... read an unsigned integer of n bytes
+func (d *dtaReader) uint(n int) uint64 {
+	b := d.bytes(n)
+	if d.err != nil {
+		return 0
+	}
+	switch n {
+	case 1:
+		return uint64(b[0])
+	case 2:
+		return uint64(d.order.Uint16(b))
+	case 4:
+		return uint64(d.order.Uint32(b))
+	}
+	return d.order.Uint64(b)
+}
+
+// str ... read a null terminated string stored in a field of n bytes
+func (d *dtaReader) str(n int) string {
+	return dtaString(d.bytes(n), d.latin1)
+}
+
+// expect ... read the given tag, failing if the file contains anything else
+func (d *dtaReader) expect(tag string) {
+	if b := d.bytes(len(tag)); d.err == nil && string(b) != tag {
+		d.err = fmt.Errorf("expected %s at %s", tag, strconv.Quote(string(b)))
+	}
+}
+
+// remaining ... obtain the number of bytes left after the current offset of the file
+func (d *dtaReader) remaining() int64 {
+	if d.err != nil {
+		return 0
+	}
+	offset, err := d.r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		d.err = err
+		return 0
+	}
+	return d.size - offset
+}
+
+// seek ... move to the given offset of the file
+func (d *dtaReader) seek(offset int64, whence int) {
+	if d.err == nil {
+		_, d.err = d.r.Seek(offset, whence)
+	}
+}
+
+// dtaString ... convert a null terminated string of a .dta file to utf-8
+func dtaString(b []byte, latin1 bool) string {
+	if end := bytes.IndexByte(b, 0); end != -1 {
+		b = b[:end]
+	}
+	if !latin1 {
+		return string(b)
+	}
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// ReadDtaFile ... obtain the variable metadata of the Stata dataset stored at the given path
+func ReadDtaFile(path string) (model.Dataset, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return model.Dataset{}, err
+	}
+	defer file.Close()
+
+	dataset, err := ReadDta(file)
+	if err != nil {
+		return dataset, model.NewError(path, 0, err)
+	}
+	dataset.Filename = path
+
+	return dataset, nil
+}
+
+// ReadDta ... obtain the variable metadata of a Stata dataset; the data itself is skipped over
+func ReadDta(r io.ReadSeeker) (model.Dataset, error) {
+
+	d := &dtaReader{r: r}
+	d.size, d.err = r.Seek(0, io.SeekEnd)
+	d.seek(0, io.SeekStart)
+	first := d.bytes(1)
+	if d.err != nil {
+		return model.Dataset{}, d.err
+	}
+	d.seek(0, io.SeekStart)
+
+	// versions 117 onwards are tagged, whereas the earlier ones start with the version
+	if first[0] == '<' {
+		return readTaggedDta(d)
+	}
+	return readLegacyDta(d)
+}
+
+// readLegacyDta ... read the metadata of a version 114 or 115 .dta file
+func readLegacyDta(d *dtaReader) (model.Dataset, error) {
+
+	version := d.uint(1)
+	if d.err == nil && version != 114 && version != 115 {
+		return model.Dataset{}, fmt.Errorf("unsupported .dta version %d", version)
+	}
+	d.order = dtaByteOrder(d.uint(1) == 1)
+	d.latin1 = true
+	d.bytes(2)
+
+	nvar := int(d.uint(2))
+	dataset := model.Dataset{Format: "Stata " + strconv.Itoa(int(version))}
+	dataset.Observations = int64(d.uint(4))
+	dataset.Label = d.str(81)
+	dataset.Created = dtaTimestamp(d.str(18))
+	if d.err != nil {
+		return dataset, d.err
+	}
+
+	// each variable takes up at least its type code, so a header giving more
+	// of them than there are bytes left is not one of a .dta file
+	if int64(nvar) > d.remaining() {
+		return dataset, fmt.Errorf("%d variables exceed the size of the file", nvar)
+	}
+
+	// the record width is needed to skip over the data
+	width := int64(0)
+	dataset.Variables = make([]model.DatasetVariable, nvar)
+	for i, code := range d.bytes(nvar) {
+		variable := &dataset.Variables[i]
+		switch {
+		case code >= 1 && code <= 244:
+			variable.Type, variable.Length = "str"+strconv.Itoa(int(code)), int(code)
+		case code == 251:
+			variable.Type, variable.Length = "byte", 1
+		case code == 252:
+			variable.Type, variable.Length = "int", 2
+		case code == 253:
+			variable.Type, variable.Length = "long", 4
+		case code == 254:
+			variable.Type, variable.Length = "float", 4
+		case code == 255:
+			variable.Type, variable.Length = "double", 8
+		default:
+			return dataset, fmt.Errorf("unknown variable type %d", code)
+		}
+		width += int64(variable.Length)
+	}
+	for i := range dataset.Variables {
+		dataset.Variables[i].Name = d.str(33)
+	}
+	d.bytes(2 * (nvar + 1))
+	for i := range dataset.Variables {
+		dataset.Variables[i].Format = d.str(49)
+	}
+	for i := range dataset.Variables {
+		dataset.Variables[i].ValueLabel = d.str(33)
+	}
+	for i := range dataset.Variables {
+		dataset.Variables[i].Label = d.str(81)
+	}
+
+	// skip the characteristics, which end with a zero type and length
+	for d.err == nil {
+		kind := d.uint(1)
+		length := d.uint(4)
+		if kind == 0 && length == 0 {
+			break
+		}
+		d.seek(int64(length), io.SeekCurrent)
+	}
+
+	d.seek(dataset.Observations*width, io.SeekCurrent)
+	if d.err != nil {
+		return dataset, d.err
+	}
+
+	// the value labels run until the end of the file
+	for {
+		length := d.uint(4)
+		if d.err == io.EOF {
+			break
+		}
+		name := d.str(33)
+		d.bytes(3)
+		table := d.bytes(int(length))
+		if d.err != nil {
+			return dataset, d.err
+		}
+		dataset.ValueLabels = append(dataset.ValueLabels, parseValueLabelTable(name, table, d.order, d.latin1))
+	}
+
+	return dataset, nil
+}
+
+// readTaggedDta ... read the metadata of a version 117, 118 or 119 .dta file
+func readTaggedDta(d *dtaReader) (model.Dataset, error) {
+
+	d.expect("<stata_dta><header><release>")
+	version, _ := strconv.Atoi(string(d.bytes(3)))
+	if d.err == nil && (version < 117 || version > 119) {
+		return model.Dataset{}, fmt.Errorf("unsupported .dta version %d", version)
+	}
+	d.expect("</release><byteorder>")
+	d.order = dtaByteOrder(string(d.bytes(3)) == "MSF")
+	d.latin1 = version == 117
+	d.expect("</byteorder><K>")
+
+	// the sizes of the fields grew along with the versions
+	nvarSize, nobsSize, labelSize, nameSize, sortSize, formatSize, varLabelSize := 2, 8, 2, 129, 2, 57, 321
+	switch version {
+	case 117:
+		nobsSize, labelSize, nameSize, formatSize, varLabelSize = 4, 1, 33, 49, 81
+	case 119:
+		nvarSize, sortSize = 4, 4
+	}
+
+	nvar := int(d.uint(nvarSize))
+	dataset := model.Dataset{Format: "Stata " + strconv.Itoa(version)}
+	d.expect("</K><N>")
+	dataset.Observations = int64(d.uint(nobsSize))
+	d.expect("</N><label>")
+	dataset.Label = d.str(int(d.uint(labelSize)))
+	d.expect("</label><timestamp>")
+	dataset.Created = dtaTimestamp(d.str(int(d.uint(1))))
+	d.expect("</timestamp></header><map>")
+	if d.err != nil {
+		return dataset, d.err
+	}
+
+	// each variable takes up at least its type code, so a header giving more
+	// of them than there are bytes left is not one of a .dta file
+	if int64(nvar)*2 > d.remaining() {
+		return dataset, fmt.Errorf("%d variables exceed the size of the file", nvar)
+	}
+
+	// offsets of each section of the file
+	offsets := make([]int64, 14)
+	for i := range offsets {
+		offsets[i] = int64(d.uint(8))
+	}
+
+	dataset.Variables = make([]model.DatasetVariable, nvar)
+	d.seek(offsets[2], io.SeekStart)
+	d.expect("<variable_types>")
+	for i := range dataset.Variables {
+		variable := &dataset.Variables[i]
+		code := d.uint(2)
+		switch {
+		case code >= 1 && code <= 2045:
+			variable.Type, variable.Length = "str"+strconv.Itoa(int(code)), int(code)
+		case code == 32768:
+			variable.Type = "strL"
+		case code == 65526:
+			variable.Type, variable.Length = "double", 8
+		case code == 65527:
+			variable.Type, variable.Length = "float", 4
+		case code == 65528:
+			variable.Type, variable.Length = "long", 4
+		case code == 65529:
+			variable.Type, variable.Length = "int", 2
+		case code == 65530:
+			variable.Type, variable.Length = "byte", 1
+		case d.err == nil:
+			return dataset, fmt.Errorf("unknown variable type %d", code)
+		}
+	}
+	d.expect("</variable_types><varnames>")
+	for i := range dataset.Variables {
+		dataset.Variables[i].Name = d.str(nameSize)
+	}
+	d.expect("</varnames><sortlist>")
+	d.bytes(sortSize * (nvar + 1))
+	d.expect("</sortlist><formats>")
+	for i := range dataset.Variables {
+		dataset.Variables[i].Format = d.str(formatSize)
+	}
+	d.expect("</formats><value_label_names>")
+	for i := range dataset.Variables {
+		dataset.Variables[i].ValueLabel = d.str(nameSize)
+	}
+	d.expect("</value_label_names><variable_labels>")
+	for i := range dataset.Variables {
+		dataset.Variables[i].Label = d.str(varLabelSize)
+	}
+	d.expect("</variable_labels>")
+
+	// each value label is enclosed in its own tags
+	d.seek(offsets[11], io.SeekStart)
+	d.expect("<value_labels>")
+	for d.err == nil {
+		tag := d.bytes(5)
+		if string(tag) != "<lbl>" {
+			break
+		}
+		length := d.uint(4)
+		name := d.str(nameSize)
+		d.bytes(3)
+		table := d.bytes(int(length))
+		d.expect("</lbl>")
+		if d.err == nil {
+			dataset.ValueLabels = append(dataset.ValueLabels, parseValueLabelTable(name, table, d.order, d.latin1))
+		}
+	}
+
+	return dataset, d.err
+}
+
+// dtaTimestamp ... convert the |dd Mon yyyy hh:mm| timestamp of a .dta file, which may be blank
+func dtaTimestamp(timestamp string) time.Time {
+	created, err := time.Parse("2 Jan 2006 15:04", strings.TrimSpace(timestamp))
+	if err != nil {
+		return time.Time{}
+	}
+	return created
+}
+
+// dtaByteOrder ... obtain the byte order of a file, given whether it is big-endian
+func dtaByteOrder(bigEndian bool) binary.ByteOrder {
+	if bigEndian {
+		return binary.BigEndian
+	}
+	return binary.LittleEndian
+}
+
+// parseValueLabelTable ... convert the table of a value label into its values and their labels
+//
+// The table consists of the number of entries, the length of the text, the
+// offset of each label within the text, the values and finally the text.
+func parseValueLabelTable(name string, table []byte, order binary.ByteOrder, latin1 bool) model.ValueLabelSet {
+
+	set := model.ValueLabelSet{Name: name}
+	if len(table) < 8 {
+		return set
+	}
+	n := int(order.Uint32(table[0:4]))
+	textStart := 8 + 8*n
+	if n < 0 || textStart > len(table) {
+		return set
+	}
+	text := table[textStart:]
+
+	for i := 0; i < n; i++ {
+		offset := int(order.Uint32(table[8+4*i:]))
+		value := int32(order.Uint32(table[8+4*n+4*i:]))
+		if offset < 0 || offset >= len(text) {
+			continue
+		}
+		set.Labels = append(set.Labels, model.ValueLabel{
+			Value: strconv.Itoa(int(value)),
+			Label: dtaString(text[offset:], latin1),
+		})
+	}
+
+	return set
+}
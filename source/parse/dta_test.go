@@ -0,0 +1,211 @@
+package parse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// fixed writes a string into a null padded field of the given width
+func fixed(buf *bytes.Buffer, str string, width int) {
+	b := make([]byte, width)
+	copy(b, str)
+	buf.Write(b)
+}
+
+// valueLabelTable assembles the value label table of the sex labels
+func valueLabelTable(order binary.ByteOrder) []byte {
+	var table bytes.Buffer
+	binary.Write(&table, order, []int32{2, 12, 0, 5, 1, 2})
+	table.WriteString("Male\x00Female\x00")
+	return table.Bytes()
+}
+
+// legacyDta assembles a version 114 dataset with two observations
+func legacyDta(order binary.ByteOrder) []byte {
+	var buf bytes.Buffer
+	byteorder := byte(2)
+	if order == binary.BigEndian {
+		byteorder = 1
+	}
+	buf.Write([]byte{114, byteorder, 1, 0})
+	binary.Write(&buf, order, int16(2))
+	binary.Write(&buf, order, int32(2))
+	fixed(&buf, "Cohort", 81)
+	fixed(&buf, "", 18)
+	buf.Write([]byte{253, 251})
+	fixed(&buf, "id", 33)
+	fixed(&buf, "sex", 33)
+	fixed(&buf, "", 6)
+	fixed(&buf, "%12.0g", 49)
+	fixed(&buf, "%8.0g", 49)
+	fixed(&buf, "", 33)
+	fixed(&buf, "sexlbl", 33)
+	fixed(&buf, "Patient identifier", 81)
+	fixed(&buf, "Sex", 81)
+	buf.Write([]byte{1})
+	binary.Write(&buf, order, int32(3))
+	buf.WriteString("abc")
+	buf.Write([]byte{0, 0, 0, 0, 0})
+	fixed(&buf, "", 2*5)
+	table := valueLabelTable(order)
+	binary.Write(&buf, order, int32(len(table)))
+	fixed(&buf, "sexlbl", 33)
+	fixed(&buf, "", 3)
+	buf.Write(table)
+	return buf.Bytes()
+}
+
+// taggedDta assembles a version 117, 118 or 119 dataset with two observations
+func taggedDta(version int, order binary.ByteOrder) []byte {
+	var buf bytes.Buffer
+	nameSize, formatSize, varLabelSize := 129, 57, 321
+	if version == 117 {
+		nameSize, formatSize, varLabelSize = 33, 49, 81
+	}
+	offsets := make([]uint64, 14)
+
+	buf.WriteString("<stata_dta><header><release>" + strconv.Itoa(version) + "</release><byteorder>")
+	if order == binary.BigEndian {
+		buf.WriteString("MSF")
+	} else {
+		buf.WriteString("LSF")
+	}
+	buf.WriteString("</byteorder><K>")
+	if version == 119 {
+		binary.Write(&buf, order, uint32(2))
+	} else {
+		binary.Write(&buf, order, uint16(2))
+	}
+	buf.WriteString("</K><N>")
+	if version == 117 {
+		binary.Write(&buf, order, uint32(2))
+	} else {
+		binary.Write(&buf, order, uint64(2))
+	}
+	buf.WriteString("</N><label>")
+	if version == 117 {
+		buf.WriteByte(6)
+	} else {
+		binary.Write(&buf, order, uint16(6))
+	}
+	buf.WriteString("Cohort</label><timestamp>")
+	buf.WriteByte(17)
+	buf.WriteString("01 Jan 2020 09:00</timestamp></header><map>")
+	mapStart := buf.Len()
+	fixed(&buf, "", 14*8)
+	buf.WriteString("</map>")
+
+	offsets[2] = uint64(buf.Len())
+	buf.WriteString("<variable_types>")
+	binary.Write(&buf, order, []uint16{65528, 65530})
+	buf.WriteString("</variable_types><varnames>")
+	fixed(&buf, "id", nameSize)
+	fixed(&buf, "sex", nameSize)
+	buf.WriteString("</varnames><sortlist>")
+	if version == 119 {
+		fixed(&buf, "", 4*3)
+	} else {
+		fixed(&buf, "", 2*3)
+	}
+	buf.WriteString("</sortlist><formats>")
+	fixed(&buf, "%12.0g", formatSize)
+	fixed(&buf, "%8.0g", formatSize)
+	buf.WriteString("</formats><value_label_names>")
+	fixed(&buf, "", nameSize)
+	fixed(&buf, "sexlbl", nameSize)
+	buf.WriteString("</value_label_names><variable_labels>")
+	fixed(&buf, "Patient identifier", varLabelSize)
+	fixed(&buf, "Sex", varLabelSize)
+	buf.WriteString("</variable_labels><characteristics></characteristics><data>")
+	buf.Write([]byte{1, 0, 0, 0, 1, 2, 0, 0, 0, 2})
+	buf.WriteString("</data><strls></strls>")
+
+	offsets[11] = uint64(buf.Len())
+	table := valueLabelTable(order)
+	buf.WriteString("<value_labels><lbl>")
+	binary.Write(&buf, order, int32(len(table)))
+	fixed(&buf, "sexlbl", nameSize)
+	fixed(&buf, "", 3)
+	buf.Write(table)
+	buf.WriteString("</lbl></value_labels></stata_dta>")
+
+	b := buf.Bytes()
+	for i, offset := range offsets {
+		order.PutUint64(b[mapStart+8*i:], offset)
+	}
+	return b
+}
+
+// mislabelledDta assembles a version 118 dataset whose header claims it is of version 119
+func mislabelledDta() []byte {
+	b := taggedDta(118, binary.LittleEndian)
+	return bytes.Replace(b, []byte("<release>118"), []byte("<release>119"), 1)
+}
+
+// overcountedDta assembles a version 119 dataset whose header claims it has four billion variables
+func overcountedDta() []byte {
+	b := taggedDta(119, binary.LittleEndian)
+	k := bytes.Index(b, []byte("<K>")) + len("<K>")
+	binary.LittleEndian.PutUint32(b[k:], 1<<32-1)
+	return b
+}
+
+// oversizedValueLabelDta assembles a version 114 dataset whose value label claims to be 2GB long
+func oversizedValueLabelDta() []byte {
+	b := legacyDta(binary.LittleEndian)
+	binary.LittleEndian.PutUint32(b[len(b)-len(valueLabelTable(binary.LittleEndian))-3-33-4:], 1<<31-1)
+	return b
+}
+
+func TestReadDta(t *testing.T) {
+	wantVariables := []model.DatasetVariable{
+		{Name: "id", Type: "long", Length: 4, Format: "%12.0g", Label: "Patient identifier"},
+		{Name: "sex", Type: "byte", Length: 1, Format: "%8.0g", Label: "Sex", ValueLabel: "sexlbl"},
+	}
+	wantValueLabels := []model.ValueLabelSet{
+		{Name: "sexlbl", Labels: []model.ValueLabel{{Value: "1", Label: "Male"}, {Value: "2", Label: "Female"}}},
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		format  string
+		wantErr bool
+	}{
+		{"version 114 little-endian", legacyDta(binary.LittleEndian), "Stata 114", false},
+		{"version 114 big-endian", legacyDta(binary.BigEndian), "Stata 114", false},
+		{"version 117", taggedDta(117, binary.LittleEndian), "Stata 117", false},
+		{"version 118 big-endian", taggedDta(118, binary.BigEndian), "Stata 118", false},
+		{"version 119", taggedDta(119, binary.LittleEndian), "Stata 119", false},
+		{"unsupported version", []byte{113, 2, 1, 0}, "", true},
+		{"truncated file", taggedDta(118, binary.LittleEndian)[:100], "", true},
+		{"truncated header", taggedDta(118, binary.LittleEndian)[:40], "", true},
+		{"version 118 layout under release 119", mislabelledDta(), "", true},
+		{"more variables than the file holds", overcountedDta(), "", true},
+		{"value label longer than the file", oversizedValueLabelDta(), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataset, err := ReadDta(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadDta() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if dataset.Format != tt.format || dataset.Label != "Cohort" || dataset.Observations != 2 {
+				t.Errorf("ReadDta() = %s %q with %d observations", dataset.Format, dataset.Label, dataset.Observations)
+			}
+			if !reflect.DeepEqual(dataset.Variables, wantVariables) {
+				t.Errorf("ReadDta() variables = %v, want %v", dataset.Variables, wantVariables)
+			}
+			if !reflect.DeepEqual(dataset.ValueLabels, wantValueLabels) {
+				t.Errorf("ReadDta() value labels = %v, want %v", dataset.ValueLabels, wantValueLabels)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+/*
+ * Functions for extracting the SAS programs of SAS Enterprise Guide projects
+ */
+
+package parse
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// CodeNode object definition
+type CodeNode struct {
+
+	// name of the node as shown in the process flow, numbered when several share it
+	Name string
+
+	// SAS program of the node
+	Code string
+}
+
+// egpElement ... an element of the project.xml of a project, read generically
+type egpElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr   `xml:",any,attr"`
+	Text     string       `xml:",chardata"`
+	Children []egpElement `xml:",any"`
+}
+
+// ParseStringForCodeNodes ... obtain the program nodes of a given SAS Enterprise Guide project
+//
+// A project is a zip archive holding a project.xml, which lists each node of
+// the project, along with a |code.sas| file for each program node that is
+// embedded in the project, e.g. |CodeTask-a1b2c3/code.sas|. Programs that
+// are linked to a file outside of the project are not read.
+func ParseStringForCodeNodes(contents string) ([]CodeNode, error) {
+
+	nodes := make([]CodeNode, 0)
+
+	archive, err := zip.NewReader(strings.NewReader(contents), int64(len(contents)))
+	if err != nil {
+		return nodes, err
+	}
+	files := make(map[string]*zip.File)
+	for _, file := range archive.File {
+		files[strings.ToLower(file.Name)] = file
+	}
+
+	if files["project.xml"] == nil {
+		return nodes, fmt.Errorf("No project.xml found in the project.")
+	}
+	projectXML, err := readZipText(files["project.xml"])
+	if err != nil {
+		return nodes, err
+	}
+
+	// the project.xml is usually UTF-16, which has been decoded already
+	var project egpElement
+	decoder := xml.NewDecoder(strings.NewReader(projectXML))
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) { return input, nil }
+	if err := decoder.Decode(&project); err != nil {
+		return nodes, err
+	}
+
+	names := make(map[string]int)
+	for _, element := range findCodeTasks(project) {
+
+		id := findElementText(element, "ID")
+		file := files[strings.ToLower(id+"/code.sas")]
+		if id == "" || file == nil {
+			continue
+		}
+		code, err := readZipText(file)
+		if err != nil {
+			return nodes, err
+		}
+
+		name := findElementText(element, "Label")
+		if name == "" {
+			name = id
+		}
+		names[name]++
+		if names[name] > 1 {
+			name += " (" + strconv.Itoa(names[name]) + ")"
+		}
+
+		nodes = append(nodes, CodeNode{Name: name, Code: strings.Replace(code, "\r\n", "\n", -1)})
+	}
+
+	return nodes, nil
+}
+
+// findCodeTasks ... obtain the elements describing a program node, in the order of the project
+func findCodeTasks(element egpElement) []egpElement {
+	for _, attr := range element.Attrs {
+		if attr.Name.Local == "Type" && strings.HasSuffix(attr.Value, ".CodeTask") {
+			return []egpElement{element}
+		}
+	}
+	tasks := make([]egpElement, 0)
+	for _, child := range element.Children {
+		tasks = append(tasks, findCodeTasks(child)...)
+	}
+	return tasks
+}
+
+// findElementText ... obtain the trimmed text of the first element of the given name within an element, or blank if there is none
+func findElementText(element egpElement, name string) string {
+	for _, child := range element.Children {
+		if child.XMLName.Local == name {
+			return strings.TrimSpace(child.Text)
+		}
+		if text := findElementText(child, name); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// readZipText ... obtain the text of a file of a zip archive, decoding it from UTF-16 when it starts with a byte order mark
+func readZipText(file *zip.File) (string, error) {
+
+	reader, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+
+	var order func([]byte) uint16
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		order = func(b []byte) uint16 { return uint16(b[0]) | uint16(b[1])<<8 }
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		order = func(b []byte) uint16 { return uint16(b[0])<<8 | uint16(b[1]) }
+	default:
+		return string(bytes.TrimPrefix(data, []byte{0xef, 0xbb, 0xbf})), nil
+	}
+
+	units := make([]uint16, 0, len(data)/2)
+	for i := 2; i+1 < len(data); i += 2 {
+		units = append(units, order(data[i:i+2]))
+	}
+	return string(utf16.Decode(units)), nil
+}
@@ -0,0 +1,104 @@
+package parse
+
+import (
+	"archive/zip"
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// testProject ... assemble an Enterprise Guide project of the given files, with a UTF-16 project.xml
+func testProject(t *testing.T, projectXML string, files map[string]string) string {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	writer, err := archive.Create("project.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded := []byte{0xff, 0xfe}
+	for _, unit := range utf16.Encode([]rune(projectXML)) {
+		encoded = append(encoded, byte(unit), byte(unit>>8))
+	}
+	writer.Write(encoded)
+	for name, contents := range files {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer.Write([]byte(contents))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.String()
+}
+
+const testProjectXML = `<?xml version="1.0" encoding="utf-16"?>
+<Project>
+  <Elements>
+    <Element Type="SAS.EG.ProjectElements.CodeTask">
+      <Element><Label>Import</Label><ID>CodeTask-a1</ID></Element>
+      <CodeTask><Embedded>True</Embedded></CodeTask>
+    </Element>
+    <Element Type="SAS.EG.ProjectElements.ShortCutToFile">
+      <Element><Label>Extract</Label><ID>ShortCutToFile-b2</ID></Element>
+    </Element>
+    <Element Type="SAS.EG.ProjectElements.CodeTask">
+      <Element><Label>Import</Label><ID>CodeTask-c3</ID></Element>
+    </Element>
+    <Element Type="SAS.EG.ProjectElements.CodeTask">
+      <Element><Label>Linked</Label><ID>CodeTask-d4</ID></Element>
+      <CodeTask><Embedded>False</Embedded></CodeTask>
+    </Element>
+  </Elements>
+</Project>`
+
+func TestParseStringForCodeNodes(t *testing.T) {
+	contents := testProject(t, testProjectXML, map[string]string{
+		"CodeTask-a1/code.sas":       "\xef\xbb\xbfdata a;\r\nrun;\r\n",
+		"codetask-c3/code.sas":       "data b; run;",
+		"ShortCutToFile-b2/data.csv": "x\n1\n",
+	})
+	got, err := ParseStringForCodeNodes(contents)
+	if err != nil {
+		t.Fatalf("ParseStringForCodeNodes() error = %v", err)
+	}
+	want := []CodeNode{{Name: "Import", Code: "data a;\nrun;\n"}, {Name: "Import (2)", Code: "data b; run;"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStringForCodeNodes() = %q, want %q", got, want)
+	}
+
+	if _, err := ParseStringForCodeNodes("data a; run;"); err == nil {
+		t.Errorf("ParseStringForCodeNodes() of a program, want an error")
+	}
+	if _, err := ParseStringForCodeNodes(testProject(t, "<Project/>", nil)[2:]); err == nil {
+		t.Errorf("ParseStringForCodeNodes() of a damaged archive, want an error")
+	}
+}
+
+func TestParseEGP(t *testing.T) {
+	contents := testProject(t, testProjectXML, map[string]string{
+		"CodeTask-a1/code.sas": "**@note Reads the extract;\n%include 'setup.sas';\n%macro m;\n%mend;\n",
+	})
+	project, err := (&Parser{}).Parse(strings.NewReader(contents), "legacy.egp")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got := make([]string, 0)
+	for _, cmt := range project.Comments {
+		got = append(got, cmt.Part+":"+strconv.Itoa(cmt.LineNum)+" "+cmt.Text)
+	}
+	for _, incl := range project.Includes {
+		got = append(got, incl.Filename+":"+strconv.Itoa(incl.LineNum)+" "+incl.Path)
+	}
+	for _, macro := range project.Macros {
+		got = append(got, macro.Filename+":"+strconv.Itoa(macro.LineNum)+" "+macro.Name)
+	}
+	want := []string{"Import:1 Reads the extract", "legacy.egp › Import:2 setup.sas", "legacy.egp › Import:3 m"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+ * Functions for reading the formats defined via PROC FORMAT
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// matches the start of a |value|, |invalue| or |picture| statement, along with the format name
+var formatStatementRegex = regexp.MustCompile("(?is)^(value|invalue|picture)\\s+(\\$?[a-zA-Z_][a-zA-Z0-9_]*)\\s*(\\([^)]*\\))?(.*)$")
+
+// ParseStringForFormats ... obtain the formats defined by the PROC FORMAT steps of a SAS string
+func ParseStringForFormats(contents string) []model.Format {
+
+	formats := make([]model.Format, 0)
+	code := StripSASComments(contents)
+	inProcFormat := false
+
+	for _, statement := range SplitSASStatements(code) {
+
+		fields := strings.Fields(strings.ToLower(statement.Text))
+		if len(fields) < 1 {
+			continue
+		}
+
+		switch {
+		case fields[0] == "proc":
+			inProcFormat = len(fields) > 1 && fields[1] == "format"
+			continue
+		case fields[0] == "data" || fields[0] == "run" || fields[0] == "quit":
+			inProcFormat = false
+			continue
+		case !inProcFormat:
+			continue
+		}
+
+		match := formatStatementRegex.FindStringSubmatch(statement.Text)
+		if match == nil {
+			continue
+		}
+
+		lineNum := LineNumberAt(code, statement.Offset)
+		format := model.Format{
+			Name:      strings.TrimPrefix(match[2], "$"),
+			Type:      strings.ToLower(match[1]),
+			Character: strings.HasPrefix(match[2], "$"),
+			LineNum:   lineNum,
+			Ranges:    parseFormatRanges(match[4]),
+			Comment:   AdjacentComment(contents, lineNum),
+		}
+		formats = append(formats, format)
+	}
+
+	return formats
+}
+
+// parseFormatRanges ... convert the |range='label' range='label'| text of a format statement into ranges
+func parseFormatRanges(text string) []model.FormatRange {
+
+	ranges := make([]model.FormatRange, 0)
+	text = strings.TrimSpace(text)
+
+	for text != "" {
+
+		// the range is everything up to the unquoted equals sign
+		equals := indexUnquoted(text, '=')
+		if equals == -1 {
+			break
+		}
+		formatRange := model.FormatRange{Range: strings.Join(strings.Fields(text[:equals]), " ")}
+		text = strings.TrimSpace(text[equals+1:])
+
+		// the label is either quoted or a single word, e.g. for an invalue
+		end := 0
+		if text != "" && (text[0] == '\'' || text[0] == '"') {
+			end = len(text)
+			formatRange.Label = text[1:]
+			if closing := strings.IndexByte(text[1:], text[0]); closing != -1 {
+				end = closing + 2
+				formatRange.Label = text[1 : end-1]
+			}
+		} else {
+			end = strings.IndexFunc(text, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' || r == '(' })
+			if end == -1 {
+				end = len(text)
+			}
+			formatRange.Label = text[:end]
+		}
+		text = strings.TrimSpace(text[end:])
+
+		// skip any options following the label, e.g. those of a picture
+		if strings.HasPrefix(text, "(") {
+			if closing := matchingParen(text); closing != -1 {
+				text = strings.TrimSpace(text[closing+1:])
+			}
+		}
+
+		ranges = append(ranges, formatRange)
+	}
+
+	return ranges
+}
+
+// indexUnquoted ... obtain the index of the first occurrence of a character outside of quotes
+func indexUnquoted(str string, c byte) int {
+	var quote byte
+	for i := 0; i < len(str); i++ {
+		switch {
+		case quote != 0:
+			if str[i] == quote {
+				quote = 0
+			}
+		case str[i] == '\'' || str[i] == '"':
+			quote = str[i]
+		case str[i] == c:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,56 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestParseStringForFormats(t *testing.T) {
+	contents := "proc format library=work;\n" +
+		"  /* Age groups */\n" +
+		"  value agegrp (default=10) low-17 = 'Child' 18-high = \"Adult\";\n" +
+		"  value $sex 'M' = 'Male' 'F' = 'Female'; /* Sex at birth */\n" +
+		"  invalue yn 'Y' = 1 'N' = 0;\n" +
+		"  picture pct low-high = '009.9%' (prefix='~');\n" +
+		"run;\n" +
+		"data a;\n" +
+		"  value = 1;\n" +
+		"run;\n"
+	want := []model.Format{
+		{Name: "agegrp", Type: "value", LineNum: 3, Comment: "Age groups",
+			Ranges: []model.FormatRange{{Range: "low-17", Label: "Child"}, {Range: "18-high", Label: "Adult"}}},
+		{Name: "sex", Type: "value", Character: true, LineNum: 4, Comment: "Sex at birth",
+			Ranges: []model.FormatRange{{Range: "'M'", Label: "Male"}, {Range: "'F'", Label: "Female"}}},
+		{Name: "yn", Type: "invalue", LineNum: 5,
+			Ranges: []model.FormatRange{{Range: "'Y'", Label: "1"}, {Range: "'N'", Label: "0"}}},
+		{Name: "pct", Type: "picture", LineNum: 6,
+			Ranges: []model.FormatRange{{Range: "low-high", Label: "009.9%"}}},
+	}
+	if got := ParseStringForFormats(contents); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStringForFormats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseFormatRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []model.FormatRange
+	}{
+		{"empty", "", []model.FormatRange{}},
+		{"quoted equals", "'a=b' = 'Equal'", []model.FormatRange{{Range: "'a=b'", Label: "Equal"}}},
+		{"list of values", "1, 2, 3 = 'Low' other = 'High'",
+			[]model.FormatRange{{Range: "1, 2, 3", Label: "Low"}, {Range: "other", Label: "High"}}},
+		{"unterminated label", "1 = 'One", []model.FormatRange{{Range: "1", Label: "One"}}},
+		{"no equals", "low-high", []model.FormatRange{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseFormatRanges(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFormatRanges() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
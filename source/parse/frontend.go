@@ -0,0 +1,190 @@
+/*
+ * The interface of language front-ends, their registry and the detection of the language of a file
+ */
+
+package parse
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// LanguageFrontend ... the language specific parts of parsing the code of a file
+type LanguageFrontend interface {
+
+	// Name ... obtain the name of the language, e.g. |model.LanguageSAS|
+	Name() string
+
+	// Extensions ... obtain the file extensions of the language, including the dot
+	Extensions() []string
+
+	// Aliases ... obtain the other names of the language, as given by shebang lines and modelines
+	Aliases() []string
+
+	// ScanComments ... obtain the comments of the code, along with any includes found among them
+	ScanComments(contents string) ([]model.Dependency, []model.Comment, error)
+
+	// ExtractDependencies ... obtain the scripts, libraries and commands the code depends on
+	//
+	// A dependency found in a part of a file, such as a cell of a notebook,
+	// gives the name of that part as its Filename, which is made into the
+	// location of the part via PartOf.
+	ExtractDependencies(contents string) []model.Dependency
+
+	// ExtractDefinitions ... record the macros, programs, datasets and such defined by the code in the project
+	ExtractDefinitions(path string, contents string, project *model.Project)
+}
+
+var (
+	// front-ends registered so far, in the order they were registered
+	frontends = make([]LanguageFrontend, 0)
+
+	// matches the |-*- mode: sas -*-| or |-*- sas -*-| emacs modeline
+	emacsModelineRegex = regexp.MustCompile("-\\*-\\s*(?:[^*]*?\\bmode:\\s*)?([a-zA-Z0-9_+-]+)[^*]*-\\*-")
+
+	// matches the |vim: set ft=stata:| or |vim: filetype=sas| vim modeline
+	vimModelineRegex = regexp.MustCompile("\\b(?:vi|vim|ex):.*?\\b(?:ft|filetype)=([a-zA-Z0-9_+-]+)")
+)
+
+// number of lines at the start and end of a file that are searched for a modeline, as in vim
+const modelineLines = 5
+
+func init() {
+	RegisterFrontend(sasFrontend{})
+	RegisterFrontend(stataFrontend{})
+	RegisterFrontend(rFrontend{})
+	RegisterFrontend(rmarkdownFrontend{})
+	RegisterFrontend(spssFrontend{})
+	RegisterFrontend(sqlFrontend{})
+	RegisterFrontend(pythonFrontend{})
+	RegisterFrontend(jupyterFrontend{})
+	RegisterFrontend(egpFrontend{})
+}
+
+// RegisterFrontend ... make a language front-end available, replacing any registered under the same name
+func RegisterFrontend(frontend LanguageFrontend) {
+	for i, registered := range frontends {
+		if registered.Name() == frontend.Name() {
+			frontends[i] = frontend
+			return
+		}
+	}
+	frontends = append(frontends, frontend)
+}
+
+// Frontends ... obtain all of the registered front-ends, in the order they were registered
+func Frontends() []LanguageFrontend {
+	return append([]LanguageFrontend{}, frontends...)
+}
+
+// Frontend ... obtain the front-end of a language given its name or one of its aliases, or nil if there is none
+func Frontend(name string) LanguageFrontend {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, frontend := range frontends {
+		if frontend.Name() == name || containsString(frontend.Aliases(), name) {
+			return frontend
+		}
+	}
+	return nil
+}
+
+// Extensions ... obtain the file extensions of all of the registered front-ends
+func Extensions() []string {
+	extensions := make([]string, 0)
+	for _, frontend := range frontends {
+		extensions = model.AppendUnique(extensions, frontend.Extensions()...)
+	}
+	sort.Strings(extensions)
+	return extensions
+}
+
+// FrontendForExtension ... obtain the front-end of a file given its extension, or nil if there is none
+func FrontendForExtension(path string) LanguageFrontend {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, frontend := range frontends {
+		if containsString(frontend.Extensions(), ext) {
+			return frontend
+		}
+	}
+	return nil
+}
+
+// DetectFrontend ... obtain the front-end of a file, or nil if its language is not known
+//
+// A modeline in the first or last few lines is the most explicit, followed by
+// a shebang line naming the interpreter, and finally the extension of the file.
+func DetectFrontend(path string, contents string) LanguageFrontend {
+
+	lines := strings.Split(contents, "\n")
+	candidates := lines
+	if len(lines) > 2*modelineLines {
+		candidates = append(append([]string{}, lines[:modelineLines]...), lines[len(lines)-modelineLines:]...)
+	}
+	for _, line := range candidates {
+		for _, re := range []*regexp.Regexp{emacsModelineRegex, vimModelineRegex} {
+			if match := re.FindStringSubmatch(line); match != nil {
+				if frontend := Frontend(match[1]); frontend != nil {
+					return frontend
+				}
+			}
+		}
+	}
+
+	if frontend := Frontend(shebangInterpreter(lines[0])); frontend != nil {
+		return frontend
+	}
+
+	return FrontendForExtension(path)
+}
+
+// shebangInterpreter ... obtain the name of the program given by a |#!/usr/bin/env stata| shebang line, if any
+func shebangInterpreter(line string) string {
+
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+
+	fields := strings.Fields(line[2:])
+	if len(fields) < 1 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+
+	// skip past the options of env to the program it runs
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = filepath.Base(field)
+				break
+			}
+		}
+	}
+
+	return interpreter
+}
+
+// SniffFrontend ... obtain the front-end named by a shebang line or modeline among the first lines of a file, if any
+func SniffFrontend(path string) LanguageFrontend {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	head := make([]byte, 1024)
+	n, _ := io.ReadFull(file, head)
+	lines := strings.SplitN(string(head[:n]), "\n", modelineLines+1)
+	if len(lines) > modelineLines {
+		lines = lines[:modelineLines]
+	}
+
+	return DetectFrontend("", strings.Join(lines, "\n"))
+}
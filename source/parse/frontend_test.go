@@ -0,0 +1,53 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// testFrontend is a front-end registered by the tests, which only scans comments
+type testFrontend struct{ stataFrontend }
+
+func (testFrontend) Name() string         { return "test" }
+func (testFrontend) Extensions() []string { return []string{".tst"} }
+func (testFrontend) Aliases() []string    { return []string{"tester"} }
+
+func TestDetectFrontend(t *testing.T) {
+	defer func(registered []LanguageFrontend) { frontends = registered }(Frontends())
+	RegisterFrontend(testFrontend{})
+	tests := []struct {
+		name     string
+		path     string
+		contents string
+		want     string
+	}{
+		{"SAS extension", "a.sas", "data a; run;\n", model.LanguageSAS},
+		{"ado-file extension", "a.ado", "program define a\nend\n", model.LanguageStata},
+		{"unknown extension", "a.txt", "some text\n", ""},
+		{"shebang", "batch", "#!/usr/local/stata/stata-mp -b\nuse a\n", model.LanguageStata},
+		{"env shebang", "batch", "#!/usr/bin/env -S tester -q\n", "test"},
+		{"emacs modeline", "a.txt", "* -*- mode: sas; coding: utf-8 -*-;\ndata a; run;\n", model.LanguageSAS},
+		{"short emacs modeline", "a.txt", "* -*- stata -*-\n", model.LanguageStata},
+		{"vim modeline over the extension", "a.sas", "data a; run;\n* vim: set ft=stata:\n", model.LanguageStata},
+		{"modeline of an unknown language", "a.sas", "* vim: set ft=cobol:\n", model.LanguageSAS},
+		{"R extension in upper case", "a.R", "x <- 1\n", model.LanguageR},
+		{"Rscript shebang", "batch", "#!/usr/bin/env Rscript\nx <- 1\n", model.LanguageR},
+		{"SQL dialect modeline", "a.txt", "-- vim: set ft=plsql:\nselect 1 from dual;\n", model.LanguageSQL},
+		{"python3 shebang", "prepare", "#!/usr/bin/env python3\nimport os\n", model.LanguagePython},
+		{"notebook extension", "a.ipynb", "{\"cells\": []}\n", model.LanguageJupyter},
+		{"Enterprise Guide project", "a.egp", "PK\x03\x04", model.LanguageEGP},
+		{"registered front-end", "a.tst", "\n", "test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if frontend := DetectFrontend(tt.path, tt.contents); frontend != nil {
+				got = frontend.Name()
+			}
+			if got != tt.want {
+				t.Errorf("DetectFrontend() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
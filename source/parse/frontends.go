@@ -0,0 +1,481 @@
+/*
+ * The built-in language front-ends
+ */
+
+package parse
+
+import "github.com/rbisewski/gommentary/source/model"
+
+// sasFrontend ... front-end of SAS programs and macro libraries
+type sasFrontend struct{}
+
+// Name ... obtain the name of the language
+func (sasFrontend) Name() string {
+	return model.LanguageSAS
+}
+
+// Extensions ... obtain the file extensions of the language
+func (sasFrontend) Extensions() []string {
+	return []string{".sas"}
+}
+
+// Aliases ... obtain the other names of the language
+func (sasFrontend) Aliases() []string {
+	return []string{}
+}
+
+// ScanComments ... obtain the comments of the code, along with the |%include| statements
+func (sasFrontend) ScanComments(contents string) ([]model.Dependency, []model.Comment, error) {
+	return ParseStringForComments(contents)
+}
+
+// ExtractDependencies ... obtain the autocall paths, references and commands the code depends on
+func (sasFrontend) ExtractDependencies(contents string) []model.Dependency {
+	return ParseStringForSASDependencies(contents)
+}
+
+// ExtractDefinitions ... record the macros, macro variables, steps, formats and paths of the code in the project
+func (sasFrontend) ExtractDefinitions(path string, contents string, project *model.Project) {
+	macros := ParseStringForMacros(path, contents)
+	project.Macros = append(project.Macros, macros...)
+	project.MacroCalls = append(project.MacroCalls, ParseStringForMacroCalls(path, contents, macros)...)
+	project.MacroVariables = append(project.MacroVariables, ParseStringForMacroVariables(path, contents, macros)...)
+	project.MacroVariableReferences = append(project.MacroVariableReferences,
+		ParseStringForMacroVariableReferences(path, contents)...)
+	for _, step := range ParseStringForSASLineage(contents) {
+		step.Filename = path
+		project.Steps = append(project.Steps, step)
+	}
+	for _, format := range ParseStringForFormats(contents) {
+		format.Filename = path
+		project.Formats = append(project.Formats, format)
+	}
+	for _, ref := range ParseStringForAbsolutePaths(StripSASComments(contents)) {
+		ref.Filename = path
+		project.Paths = append(project.Paths, ref)
+	}
+}
+
+// stataFrontend ... front-end of Stata do-files and ado-files
+type stataFrontend struct{}
+
+// Name ... obtain the name of the language
+func (stataFrontend) Name() string {
+	return model.LanguageStata
+}
+
+// Extensions ... obtain the file extensions of the language
+func (stataFrontend) Extensions() []string {
+	return []string{".do", ".ado"}
+}
+
+// Aliases ... obtain the other names of the language, including those of the Stata executables
+func (stataFrontend) Aliases() []string {
+	return []string{"ado", "stata-mp", "stata-se", "stata-ic", "statamp", "statase", "xstata", "xstata-mp", "xstata-se"}
+}
+
+// ScanComments ... obtain the comments of the code, which follow the same notation as in SAS
+func (stataFrontend) ScanComments(contents string) ([]model.Dependency, []model.Comment, error) {
+	return ParseStringForComments(contents)
+}
+
+// ExtractDependencies ... obtain the do / run / include dependencies and commands of the code
+func (stataFrontend) ExtractDependencies(contents string) []model.Dependency {
+	return ParseStringForStataDependencies(contents)
+}
+
+// ExtractDefinitions ... record the programs, data commands and paths of the code in the project
+func (stataFrontend) ExtractDefinitions(path string, contents string, project *model.Project) {
+	project.Programs = append(project.Programs, ParseStringForPrograms(path, contents)...)
+	for _, step := range ParseStringForStataLineage(contents) {
+		step.Filename = path
+		project.Steps = append(project.Steps, step)
+	}
+	for _, ref := range ParseStringForAbsolutePaths(StripStataComments(contents)) {
+		ref.Filename = path
+		project.Paths = append(project.Paths, ref)
+	}
+}
+
+// rFrontend ... front-end of R scripts
+type rFrontend struct{}
+
+// Name ... obtain the name of the language
+func (rFrontend) Name() string {
+	return model.LanguageR
+}
+
+// Extensions ... obtain the file extensions of the language, which are given in either case
+func (rFrontend) Extensions() []string {
+	return []string{".R", ".r"}
+}
+
+// Aliases ... obtain the other names of the language, including that of the script runner
+func (rFrontend) Aliases() []string {
+	return []string{"rscript", "ess-r"}
+}
+
+// ScanComments ... obtain the |#| and |#'| roxygen comments of the code, which include nothing
+func (rFrontend) ScanComments(contents string) ([]model.Dependency, []model.Comment, error) {
+	return []model.Dependency{}, ParseStringForRComments(contents), nil
+}
+
+// ExtractDependencies ... obtain the sourced scripts, attached packages and external commands of the code
+func (rFrontend) ExtractDependencies(contents string) []model.Dependency {
+	return ParseStringForRDependencies(contents)
+}
+
+// ExtractDefinitions ... record the functions and paths of the code in the project
+func (rFrontend) ExtractDefinitions(path string, contents string, project *model.Project) {
+	project.Macros = append(project.Macros, ParseStringForRFunctions(path, contents)...)
+	for _, ref := range ParseStringForAbsolutePaths(StripRComments(contents)) {
+		ref.Filename = path
+		project.Paths = append(project.Paths, ref)
+	}
+}
+
+// rmarkdownFrontend ... front-end of R Markdown and Quarto documents, which hands each chunk to the front-end of its engine
+type rmarkdownFrontend struct{}
+
+// Name ... obtain the name of the language
+func (rmarkdownFrontend) Name() string {
+	return model.LanguageRMarkdown
+}
+
+// Extensions ... obtain the file extensions of R Markdown and Quarto documents, which are given in either case
+func (rmarkdownFrontend) Extensions() []string {
+	return []string{".Rmd", ".rmd", ".qmd"}
+}
+
+// Aliases ... obtain the other names of the language
+func (rmarkdownFrontend) Aliases() []string {
+	return []string{"rmd", "quarto", "qmd"}
+}
+
+// ScanComments ... obtain the comments of each chunk, labelled by the chunk, along with any includes among them
+func (rmarkdownFrontend) ScanComments(contents string) ([]model.Dependency, []model.Comment, error) {
+
+	includes := make([]model.Dependency, 0)
+	comments := make([]model.Comment, 0)
+
+	for _, chunk := range ParseStringForChunks(contents) {
+		frontend := chunkFrontend(chunk)
+		if frontend == nil {
+			continue
+		}
+		included, parsed, err := frontend.ScanComments(chunkContents(chunk))
+		if err != nil {
+			return includes, comments, err
+		}
+		for _, incl := range included {
+			incl.Filename = chunk.Label
+			includes = append(includes, incl)
+		}
+		for _, cmt := range parsed {
+			cmt.Part = chunk.Label
+			comments = append(comments, cmt)
+		}
+	}
+
+	return includes, comments, nil
+}
+
+// ExtractDependencies ... obtain the dependencies of each chunk, labelled by the chunk
+func (rmarkdownFrontend) ExtractDependencies(contents string) []model.Dependency {
+	dependencies := make([]model.Dependency, 0)
+	for _, chunk := range ParseStringForChunks(contents) {
+		frontend := chunkFrontend(chunk)
+		if frontend == nil {
+			continue
+		}
+		for _, dependency := range frontend.ExtractDependencies(chunkContents(chunk)) {
+			dependency.Filename = chunk.Label
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies
+}
+
+// ExtractDefinitions ... record the definitions of each chunk in the project, located in the chunk
+func (rmarkdownFrontend) ExtractDefinitions(path string, contents string, project *model.Project) {
+	for _, chunk := range ParseStringForChunks(contents) {
+		if frontend := chunkFrontend(chunk); frontend != nil {
+			frontend.ExtractDefinitions(model.PartOf(path, chunk.Label), chunkContents(chunk), project)
+		}
+	}
+}
+
+// spssFrontend ... front-end of SPSS syntax files
+type spssFrontend struct{}
+
+// Name ... obtain the name of the language
+func (spssFrontend) Name() string {
+	return model.LanguageSPSS
+}
+
+// Extensions ... obtain the file extensions of the language
+func (spssFrontend) Extensions() []string {
+	return []string{".sps"}
+}
+
+// Aliases ... obtain the other names of the language, including that of its free counterpart
+func (spssFrontend) Aliases() []string {
+	return []string{"pspp"}
+}
+
+// ScanComments ... obtain the |*|, |COMMENT| and |/* */| comments of the code, which include nothing
+func (spssFrontend) ScanComments(contents string) ([]model.Dependency, []model.Comment, error) {
+	return []model.Dependency{}, ParseStringForSPSSComments(contents), nil
+}
+
+// ExtractDependencies ... obtain the included files, file handles and host commands of the code
+func (spssFrontend) ExtractDependencies(contents string) []model.Dependency {
+	return ParseStringForSPSSDependencies(contents)
+}
+
+// ExtractDefinitions ... record the datasets read and written, along with the paths, of the code in the project
+func (spssFrontend) ExtractDefinitions(path string, contents string, project *model.Project) {
+	for _, step := range ParseStringForSPSSLineage(contents) {
+		step.Filename = path
+		project.Steps = append(project.Steps, step)
+	}
+	for _, ref := range ParseStringForAbsolutePaths(StripSPSSComments(contents)) {
+		ref.Filename = path
+		project.Paths = append(project.Paths, ref)
+	}
+}
+
+// sqlFrontend ... front-end of SQL scripts
+type sqlFrontend struct{}
+
+// Name ... obtain the name of the language
+func (sqlFrontend) Name() string {
+	return model.LanguageSQL
+}
+
+// Extensions ... obtain the file extensions of the language
+func (sqlFrontend) Extensions() []string {
+	return []string{".sql"}
+}
+
+// Aliases ... obtain the other names of the language, including its dialects and their clients
+func (sqlFrontend) Aliases() []string {
+	return []string{"psql", "pgsql", "plsql", "sqlplus", "tsql", "mysql", "sqlite3"}
+}
+
+// ScanComments ... obtain the |--| and |/* */| comments of the code, which include nothing
+func (sqlFrontend) ScanComments(contents string) ([]model.Dependency, []model.Comment, error) {
+	return []model.Dependency{}, ParseStringForSQLComments(contents), nil
+}
+
+// ExtractDependencies ... obtain the scripts run and external commands of the code
+func (sqlFrontend) ExtractDependencies(contents string) []model.Dependency {
+	return ParseStringForSQLDependencies(contents)
+}
+
+// ExtractDefinitions ... record the tables read and written, along with the paths, of the code in the project
+func (sqlFrontend) ExtractDefinitions(path string, contents string, project *model.Project) {
+	for _, step := range ParseStringForSQLLineage(contents) {
+		step.Filename = path
+		project.Steps = append(project.Steps, step)
+	}
+	for _, ref := range ParseStringForAbsolutePaths(StripSQLComments(contents)) {
+		ref.Filename = path
+		project.Paths = append(project.Paths, ref)
+	}
+}
+
+// pythonFrontend ... front-end of Python scripts and modules
+type pythonFrontend struct{}
+
+// Name ... obtain the name of the language
+func (pythonFrontend) Name() string {
+	return model.LanguagePython
+}
+
+// Extensions ... obtain the file extensions of the language
+func (pythonFrontend) Extensions() []string {
+	return []string{".py"}
+}
+
+// Aliases ... obtain the other names of the language, including those of its interpreters
+func (pythonFrontend) Aliases() []string {
+	return []string{"python3", "python2", "py", "ipython", "ipython3"}
+}
+
+// ScanComments ... obtain the |#| comments and docstrings of the code, which include nothing
+func (pythonFrontend) ScanComments(contents string) ([]model.Dependency, []model.Comment, error) {
+	return []model.Dependency{}, ParseStringForPythonComments(contents), nil
+}
+
+// ExtractDependencies ... obtain the imported modules, scripts run and external commands of the code
+func (pythonFrontend) ExtractDependencies(contents string) []model.Dependency {
+	return ParseStringForPythonDependencies(contents)
+}
+
+// ExtractDefinitions ... record the functions and classes, along with the paths, of the code in the project
+func (pythonFrontend) ExtractDefinitions(path string, contents string, project *model.Project) {
+	project.Macros = append(project.Macros, ParseStringForPythonFunctions(path, contents)...)
+	for _, ref := range ParseStringForAbsolutePaths(StripPythonComments(contents)) {
+		ref.Filename = path
+		project.Paths = append(project.Paths, ref)
+	}
+}
+
+// jupyterFrontend ... front-end of Jupyter notebooks, which hands each code cell to the front-end of the kernel language
+type jupyterFrontend struct{}
+
+// Name ... obtain the name of the language
+func (jupyterFrontend) Name() string {
+	return model.LanguageJupyter
+}
+
+// Extensions ... obtain the file extensions of the language
+func (jupyterFrontend) Extensions() []string {
+	return []string{".ipynb"}
+}
+
+// Aliases ... obtain the other names of the language
+func (jupyterFrontend) Aliases() []string {
+	return []string{"ipynb", "notebook"}
+}
+
+// ScanComments ... obtain the comments of each code cell and the paragraphs of each markdown cell, labelled by the cell
+func (jupyterFrontend) ScanComments(contents string) ([]model.Dependency, []model.Comment, error) {
+
+	includes := make([]model.Dependency, 0)
+	comments := make([]model.Comment, 0)
+
+	notebook, err := ParseStringForNotebook(contents)
+	if err != nil {
+		return includes, comments, err
+	}
+	kernel := notebookKernel(notebook)
+
+	for _, cell := range notebook.Cells {
+
+		included := make([]model.Dependency, 0)
+		parsed := make([]model.Comment, 0)
+		switch {
+		case cell.Kind == "markdown":
+			parsed = ParseStringForMarkdownNarrative(cell.Source)
+		case cell.Kind == "code" && kernel != nil:
+			if included, parsed, err = kernel.ScanComments(cell.Source); err != nil {
+				return includes, comments, err
+			}
+		}
+
+		for _, incl := range included {
+			incl.Filename = cellPart(cell)
+			includes = append(includes, incl)
+		}
+		for _, cmt := range parsed {
+			cmt.Part = cellPart(cell)
+			comments = append(comments, cmt)
+		}
+	}
+
+	return includes, comments, nil
+}
+
+// ExtractDependencies ... obtain the dependencies of each code cell, labelled by the cell
+func (jupyterFrontend) ExtractDependencies(contents string) []model.Dependency {
+	dependencies := make([]model.Dependency, 0)
+	notebook, err := ParseStringForNotebook(contents)
+	kernel := notebookKernel(notebook)
+	if err != nil || kernel == nil {
+		return dependencies
+	}
+	for _, cell := range notebook.Cells {
+		if cell.Kind != "code" {
+			continue
+		}
+		for _, dependency := range kernel.ExtractDependencies(cell.Source) {
+			dependency.Filename = cellPart(cell)
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies
+}
+
+// ExtractDefinitions ... record the definitions of each code cell in the project, located in the cell
+func (jupyterFrontend) ExtractDefinitions(path string, contents string, project *model.Project) {
+	notebook, err := ParseStringForNotebook(contents)
+	kernel := notebookKernel(notebook)
+	if err != nil || kernel == nil {
+		return
+	}
+	for _, cell := range notebook.Cells {
+		if cell.Kind == "code" {
+			kernel.ExtractDefinitions(model.PartOf(path, cellPart(cell)), cell.Source, project)
+		}
+	}
+}
+
+// egpFrontend ... front-end of SAS Enterprise Guide projects, which hands each program node to the registered SAS front-end
+type egpFrontend struct{}
+
+// Name ... obtain the name of the language
+func (egpFrontend) Name() string {
+	return model.LanguageEGP
+}
+
+// Extensions ... obtain the file extensions of the language
+func (egpFrontend) Extensions() []string {
+	return []string{".egp"}
+}
+
+// Aliases ... obtain the other names of the language
+func (egpFrontend) Aliases() []string {
+	return []string{"enterprise-guide"}
+}
+
+// ScanComments ... obtain the comments of each program node, labelled by the node, along with any includes among them
+func (egpFrontend) ScanComments(contents string) ([]model.Dependency, []model.Comment, error) {
+
+	includes := make([]model.Dependency, 0)
+	comments := make([]model.Comment, 0)
+
+	nodes, err := ParseStringForCodeNodes(contents)
+	if err != nil {
+		return includes, comments, err
+	}
+
+	for _, node := range nodes {
+		included, parsed, err := Frontend(model.LanguageSAS).ScanComments(node.Code)
+		if err != nil {
+			return includes, comments, err
+		}
+		for _, incl := range included {
+			incl.Filename = node.Name
+			includes = append(includes, incl)
+		}
+		for _, cmt := range parsed {
+			cmt.Part = node.Name
+			comments = append(comments, cmt)
+		}
+	}
+
+	return includes, comments, nil
+}
+
+// ExtractDependencies ... obtain the dependencies of each program node, labelled by the node
+func (egpFrontend) ExtractDependencies(contents string) []model.Dependency {
+	dependencies := make([]model.Dependency, 0)
+	nodes, _ := ParseStringForCodeNodes(contents)
+	for _, node := range nodes {
+		for _, dependency := range Frontend(model.LanguageSAS).ExtractDependencies(node.Code) {
+			dependency.Filename = node.Name
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	return dependencies
+}
+
+// ExtractDefinitions ... record the definitions of each program node in the project, located in the node
+func (egpFrontend) ExtractDefinitions(path string, contents string, project *model.Project) {
+	nodes, _ := ParseStringForCodeNodes(contents)
+	for _, node := range nodes {
+		Frontend(model.LanguageSAS).ExtractDefinitions(model.PartOf(path, node.Name), node.Code, project)
+	}
+}
@@ -0,0 +1,534 @@
+/*
+ * Useful functions for reading the comments and code of a project
+ */
+
+package parse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches the |@keyword | of a comment, wherever it appears in the raw text
+	rawCommentKeywordRegex = regexp.MustCompile("@[^@\\s]+\\s")
+
+	// matches an |@keyword| at the start of the text of a comment
+	leadingKeywordRegex = regexp.MustCompile("^@[^@\\s]+(\\s|$)")
+)
+
+// ReadProjectFromDirectory ... search through all files in a given directory for comments and macros
+// TODO: add logic to this file to handle the "group under" functionality
+//
+// A file that cannot be read or parsed does not stop the others from being
+// read; the errors of all such files are returned together as a
+// |model.ErrorList|, along with the project of every file that could be read.
+func ReadProjectFromDirectory(codeDir string, filetypes []string) (model.Project, error) {
+
+	if codeDir == "" {
+		return model.Project{}, fmt.Errorf("Code directory name is invalid")
+	}
+
+	listOfFilesToRead := make([]string, 0)
+	project := model.Project{}
+
+	codeDirContents, err := ioutil.ReadDir(codeDir)
+	if err != nil {
+		return project, err
+	}
+
+	// obtain the list of files to read
+	for _, file := range codeDirContents {
+
+		// skip directory pointers
+		if file.Name() == "." || file.Name() == ".." {
+			continue
+		}
+
+		// check if file is a valid type
+		isAcceptedFiletype := false
+		for _, t := range filetypes {
+			if strings.HasSuffix(file.Name(), t) {
+				isAcceptedFiletype = true
+				break
+			}
+		}
+
+		filename := filepath.Join(codeDir, file.Name())
+
+		// skip files that are non-accepted file types, unless their first
+		// lines name a known language, as do shebang lines and modelines
+		if !isAcceptedFiletype && (file.IsDir() || SniffFrontend(filename) == nil) {
+			continue
+		}
+
+		listOfFilesToRead = append(listOfFilesToRead, filename)
+	}
+
+	if len(listOfFilesToRead) < 1 {
+		return project, fmt.Errorf("No parsable files were found. Exiting...")
+	}
+
+	// using the list of files, read each of them
+	errs := make(model.ErrorList, 0)
+	for _, path := range listOfFilesToRead {
+
+		parsed, err := parseFile(path)
+		if err != nil {
+			errs.Add(path, 0, err)
+			continue
+		}
+
+		project.Add(parsed)
+	}
+
+	errs = append(errs, readIncludedMacroLibraries(codeDir, &project)...)
+	Finish(&project)
+
+	return project, errs.Err()
+}
+
+// Finish ... complete a project once all of its files have been added, with what can only be known from all of them
+//
+// Included scripts that turn out to define macros are made macro libraries,
+// and the data dictionary is assembled from the |@var| comments, as the
+// datasets they belong to are known once the lineage is. Projects read via
+// ReadProjectFromDirectory are already finished, whereas those put together
+// via Parser.Parse and Project.Add are to be finished before being rendered.
+func Finish(project *model.Project) {
+
+	definesMacros := make(map[string]bool)
+	for _, macro := range project.Macros {
+		definesMacros[filepath.Clean(macro.Filename)] = true
+	}
+	for i, incl := range project.Includes {
+		if incl.Kind == model.DependencyScript && incl.Statement == "%include" &&
+			includesMacros(definesMacros, incl.Filename, incl.Path) {
+			project.Includes[i].Kind = model.DependencyMacro
+		}
+	}
+
+	project.Variables = VariablesFromComments(project.Comments, project.Steps)
+}
+
+// includesMacros ... whether an included path refers to one of the given files that define macros
+//
+// The path is looked for as given by includeCandidates, and finally by its
+// filename alone, as the files need not have been read from disk.
+func includesMacros(definesMacros map[string]bool, includingFile string, rawPath string) bool {
+	candidates := includeCandidates(includingFile, rawPath)
+	for _, candidate := range candidates {
+		if definesMacros[filepath.Clean(candidate)] {
+			return true
+		}
+	}
+	if len(candidates) < 1 || filepath.IsAbs(candidates[0]) {
+		return false
+	}
+	for path := range definesMacros {
+		if filepath.Base(path) == filepath.Base(candidates[0]) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFile ... read a single file of a project, turning any panic of its parser into an error of that file
+//
+// The language of the file is detected from its contents and extension. A
+// parser that trips over code it does not expect must not take the other
+// files of the project down with it.
+func parseFile(path string) (project model.Project, err error) {
+
+	defer recoverParser(path, &err)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return project, err
+	}
+	defer file.Close()
+
+	parser := &Parser{}
+	return parser.Parse(file, path)
+}
+
+// recoverParser ... turn a panic while parsing the given file into an error of that file, if there was one
+//
+// This is only of use when deferred.
+func recoverParser(path string, err *error) {
+	if r := recover(); r != nil {
+		*err = model.NewError(path, 0, fmt.Errorf("Parsing failed unexpectedly: %v", r))
+	}
+}
+
+// readIncludedMacroLibraries ... read the macros of included files that reside outside of the code directory
+func readIncludedMacroLibraries(codeDir string, project *model.Project) model.ErrorList {
+
+	errs := make(model.ErrorList, 0)
+
+	alreadyRead := make(map[string]bool)
+	for _, path := range project.Files {
+		alreadyRead[filepath.Clean(path)] = true
+	}
+
+	// libraries may include further libraries, so keep going until no new
+	// includes have been found
+	for i := 0; i < len(project.Includes); i++ {
+
+		incl := project.Includes[i]
+		if incl.Statement != "%include" {
+			continue
+		}
+		path, found := ResolveIncludePath(codeDir, incl.Filename, incl.Path)
+		if !found || alreadyRead[path] {
+			continue
+		}
+		alreadyRead[path] = true
+
+		library, err := readMacroLibrary(path)
+		if err != nil {
+			errs.Add(path, 0, err)
+			continue
+		}
+		project.Add(library)
+	}
+
+	return errs
+}
+
+// readMacroLibrary ... read the includes, macros and macro variables of an included SAS file
+func readMacroLibrary(path string) (library model.Project, err error) {
+
+	defer recoverParser(path, &err)
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return library, err
+	}
+	contents := string(bytes)
+	if contents == "" {
+		return library, nil
+	}
+
+	included, _, err := ParseStringForComments(contents)
+	if err != nil {
+		return library, err
+	}
+	for _, libraryIncl := range included {
+		libraryIncl.Filename = path
+		library.Includes = append(library.Includes, libraryIncl)
+	}
+
+	macros := ParseStringForMacros(path, contents)
+	library.Macros = macros
+	library.MacroCalls = ParseStringForMacroCalls(path, contents, macros)
+	library.MacroVariables = ParseStringForMacroVariables(path, contents, macros)
+	library.MacroVariableReferences = ParseStringForMacroVariableReferences(path, contents)
+	for _, ref := range ParseStringForAbsolutePaths(StripSASComments(contents)) {
+		ref.Filename = path
+		library.Paths = append(library.Paths, ref)
+	}
+
+	return library, nil
+}
+
+// GetLineNumber ... obtain the current line number a comment as defined by (startIndex, endIndex) appears on
+func GetLineNumber(lines [][]int, pos []int) (int, error) {
+	if len(pos) != 2 {
+		return -1, fmt.Errorf("Invalid comment indices given during line reconstruction attempt.")
+	}
+
+	end := pos[1]
+
+	for i, line := range lines {
+		if len(line) != 2 {
+			return -1, fmt.Errorf("Line mismatched, file is likely corrupted.")
+		}
+		// check against the end of the next line
+		if line[1] >= end {
+			return i + 1, nil
+		}
+	}
+
+	// past the final newline is the last line, which need not end with one
+	return len(lines) + 1, nil
+}
+
+// ParseStringForComments ... obtain all comments from a given string
+// TODO: functionalize and clean up parts of the regex logic used
+func ParseStringForComments(contents string) ([]model.Dependency, []model.Comment, error) {
+	if contents == "" {
+		return []model.Dependency{}, []model.Comment{}, nil
+	}
+
+	asterixComment := regexp.MustCompile("@[^@]+")
+	whitespaceRegexes := []string{"\t", "\r", "\f", "\v"}
+	includeStrings := make([]model.RawInclude, 0)
+	commentStrings := make([]model.RawComment, 0)
+	includes := make([]model.Dependency, 0)
+	comments := make([]model.Comment, 0)
+
+	// obtain newline indices, helpful for reconstructing line numbers
+	newlineRegex := "\n"
+	reNewline := regexp.MustCompile(newlineRegex)
+	lineIndices := reNewline.FindAllStringIndex(contents, -1)
+
+	//
+	// Clean away complicated whitespace characters
+	//
+
+	// clean up unneeded whitespace characters
+	contents = reNewline.ReplaceAllString(contents, " ")
+	for _, str := range whitespaceRegexes {
+		re := regexp.MustCompile(str)
+		contents = re.ReplaceAllString(contents, "")
+	}
+
+	//
+	// Handle the different comment types here
+	//
+
+	// buffer the contents with a space to allow for regexes that check for initial characters
+	contents = " " + contents
+
+	// handle the |%include '/path/to/macro.sas';| include statements
+	includeMacroLine := regexp.MustCompile("%include\\s+[^;]+;")
+	sindices := includeMacroLine.FindAllStringIndex(contents, -1)
+	for _, sindex := range sindices {
+		start := sindex[0] + 1
+		end := sindex[1]
+		// take into account very short / empty lines
+		if start == end {
+			continue
+		}
+		// get the line number the %include starts on
+		num, err := GetLineNumber(lineIndices, sindex)
+		if err != nil {
+			return nil, nil, err
+		}
+		raw := contents[start:end]
+		includeStrings = append(includeStrings, model.RawInclude{LineNum: num, Text: raw})
+	}
+
+	// handle the |**@keyword ;| comments
+	twoAsterixAtEndsWithSemicolon := regexp.MustCompile("[^\\/]\\s{0,}\\*\\*@[a-zA-Z\\.]+ [^;]+;")
+	sidx1 := twoAsterixAtEndsWithSemicolon.FindAllStringIndex(contents, -1)
+
+	// handle the |** ;| comments
+	twoAsterixEndsWithSemicolon := regexp.MustCompile("[^\\/]\\s{0,}\\*\\*[a-zA-Z\\.]+ [^;]+;")
+	sidx2 := twoAsterixEndsWithSemicolon.FindAllStringIndex(contents, -1)
+
+	// cycle through all of the gathered results
+	sindices = append(sidx1, sidx2...)
+	for _, sindex := range sindices {
+		start := sindex[0] + 1
+		end := sindex[1]
+		// take into account very short / empty comments
+		if start == end {
+			continue
+		}
+		// get the line number the comment starts on
+		num, err := GetLineNumber(lineIndices, sindex)
+		if err != nil {
+			return nil, nil, err
+		}
+		raw := contents[start:end]
+		commentStrings = append(commentStrings, model.RawComment{LineNum: num, Text: raw})
+	}
+
+	// handle the |/**@ */| comments
+	slashTwoAsterixAtEndsWithSlash := regexp.MustCompile("\\/\\s{0,}\\*\\*\\s{0,}@[a-zA-Z\\.]+ [^\\/]+\\*\\/")
+	sindices = slashTwoAsterixAtEndsWithSlash.FindAllStringIndex(contents, -1)
+	for _, sindex := range sindices {
+		start := sindex[0] + 1
+		end := sindex[1]
+		// take into account very short / empty comments
+		if start == end {
+			continue
+		}
+		// get the line number the comment starts on
+		num, err := GetLineNumber(lineIndices, sindex)
+		if err != nil {
+			return nil, nil, err
+		}
+		raw := contents[start:end]
+
+		// if there is only a single @ comment, just use the whole match
+		pieces := asterixComment.FindAllString(raw, -1)
+		if len(pieces) == 1 {
+			commentStrings = append(commentStrings, model.RawComment{LineNum: num, Text: raw})
+			continue
+		}
+
+		// handle comments of the type...
+		//
+		//    /**
+		//     @main :title   Experiment #42
+		//     @main :author  John Smith
+		//     @main :org     University of Manitoba
+		//     @main This experiment is designed to take into account the answer to life, the universe, and everything.
+		//    */
+		//
+		for _, piece := range pieces {
+			commentStrings = append(commentStrings, model.RawComment{LineNum: num, Text: piece})
+		}
+	}
+
+	//
+	// Convert the raw include statements into the actual macros imported
+	//
+
+	for _, str := range includeStrings {
+
+		// trim away unneeded chars
+		sansSemicolon := strings.Trim(str.Text, ";")
+		sansSpaces := strings.TrimSpace(sansSemicolon)
+
+		pieces := strings.Split(sansSpaces, "include")
+
+		// skip empty or improper entries, if any
+		if len(pieces) < 2 || pieces[1] == "" {
+			continue
+		}
+
+		// trim " and ' chars to obtain the included path, along with
+		// any spaces inside of the included path, if any
+		sansDoubleQuotes := strings.Trim(pieces[1], "\"")
+		sansQuotes := strings.Trim(sansDoubleQuotes, "'")
+		sansPathSpaces := strings.TrimSpace(sansQuotes)
+		sansFewerQuotes := strings.Trim(sansPathSpaces, "'")
+		sansFewerDoubleQuotes := strings.Trim(sansFewerQuotes, "\"")
+		rawPath := sansFewerDoubleQuotes
+
+		// skip empty entries, if any
+		if rawPath == "" {
+			continue
+		}
+
+		// paths mentioning the word macro are assumed to be macro libraries,
+		// the remainder are scripts until their contents are known
+		kind := model.DependencyScript
+		if strings.Contains(strings.ToLower(rawPath), "macro") {
+			kind = model.DependencyMacro
+		}
+
+		// if got this far, then probably is a path, so create an include entry, then append it
+		newDependency := model.Dependency{LineNum: str.LineNum, Path: rawPath, Statement: "%include", Kind: kind}
+		includes = append(includes, newDependency)
+	}
+
+	//
+	// Convert the raw comment text into meaningful comments
+	//
+
+	// attempt to convert the above comment strings to comments
+	for _, str := range commentStrings {
+		comments = append(comments, commentFromRaw(str))
+	}
+
+	return includes, comments, nil
+}
+
+// lineComments object definition, which forms comments out of the consecutive line comments of a file
+type lineComments struct {
+
+	// comments formed so far
+	comments []model.Comment
+
+	// index of the comment being continued, or -1 if there is none
+	current int
+
+	// whether the comment being continued is left out
+	skipping bool
+
+	// line of the previous line comment
+	previousLine int
+
+	// kind of the previous line comment
+	previousKind string
+}
+
+// newLineComments ... create a new, empty, set of comments formed out of line comments
+func newLineComments() *lineComments {
+	return &lineComments{comments: make([]model.Comment, 0), current: -1}
+}
+
+// add ... add the text of a line comment, without its marker, found on a given line
+//
+// A comment that is all there is on its line continues the one on the line
+// before it, if that is of the same kind and also on a line of its own,
+// unless it starts with an |@keyword|. The lines of a comment are joined by
+// spaces. A comment that is skipped takes the lines continuing it with it.
+func (c *lineComments) add(lineNum int, wholeLine bool, kind string, text string, skip bool) {
+
+	keyword := leadingKeywordRegex.FindString(text)
+	continued := wholeLine && (c.current != -1 || c.skipping) && lineNum == c.previousLine+1 &&
+		kind == c.previousKind && keyword == ""
+	c.previousLine, c.previousKind = lineNum, kind
+
+	if continued {
+		if !c.skipping && text != "" {
+			c.comments[c.current].Text = strings.TrimSpace(c.comments[c.current].Text + " " + text)
+		}
+		return
+	}
+
+	c.end()
+	if skip || (keyword == "" && text == "") {
+		c.skipping = skip && wholeLine
+		return
+	}
+
+	newComment := model.Comment{LineNum: lineNum, Text: strings.TrimSpace(text[len(keyword):])}
+	if keyword != "" {
+		newComment.Keyword = strings.TrimSpace(keyword) + " "
+	}
+	c.comments = append(c.comments, newComment)
+	if wholeLine {
+		c.current = len(c.comments) - 1
+	}
+}
+
+// end ... stop continuing the comment of the previous line, as when something other than a line comment follows it
+func (c *lineComments) end() {
+	c.current = -1
+	c.skipping = false
+}
+
+// commentFromRaw ... convert the raw text of a comment into a comment, taking its keyword from the first |@keyword |, if any
+func commentFromRaw(str model.RawComment) model.Comment {
+
+	newComment := model.Comment{LineNum: str.LineNum}
+
+	// obtain the keyword, if any
+	match := rawCommentKeywordRegex.FindString(str.Text)
+
+	// handle the comments that have keywords...
+	if match != "" {
+		newComment.Keyword = match
+		text := rawCommentKeywordRegex.Split(str.Text, -1)
+		if len(text) > 1 {
+			newComment.Text = text[1]
+		}
+
+	} else {
+		// ... else just use the whole string as a comment
+		newComment.Text = str.Text
+	}
+
+	// cleanup text
+	newComment.Text = strings.TrimSpace(newComment.Text)
+	newComment.Text = strings.TrimSuffix(newComment.Text, ";")
+	newComment.Text = strings.TrimPrefix(newComment.Text, "**")
+	newComment.Text = strings.TrimPrefix(newComment.Text, "/*")
+	newComment.Text = strings.TrimSuffix(newComment.Text, "*/")
+	newComment.Text = strings.TrimSpace(newComment.Text)
+
+	return newComment
+}
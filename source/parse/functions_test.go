@@ -0,0 +1,166 @@
+package parse
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestGetLineNumber(t *testing.T) {
+	lines := [][]int{{4, 5}, {9, 10}}
+	tests := []struct {
+		name    string
+		lines   [][]int
+		pos     []int
+		want    int
+		wantErr bool
+	}{
+		{"first line", lines, []int{0, 3}, 1, false},
+		{"second line", lines, []int{6, 10}, 2, false},
+		{"last line without a newline", lines, []int{11, 14}, 3, false},
+		{"single line file", [][]int{}, []int{0, 3}, 1, false},
+		{"invalid indices", lines, []int{0}, -1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetLineNumber(tt.lines, tt.pos)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("GetLineNumber() = %d, %v, want %d, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseStringForCommentsIncludes(t *testing.T) {
+	contents := "%include \"project_script.sas\";\n" +
+		"%include 'lib/macros.sas';\n" +
+		"%include \" \";\n"
+	includes, _, err := ParseStringForComments(contents)
+	if err != nil {
+		t.Fatalf("ParseStringForComments() error = %v", err)
+	}
+	got := make([]string, 0)
+	for _, include := range includes {
+		got = append(got, include.Kind+" "+include.Path)
+	}
+	if want := []string{"script project_script.sas", "macro lib/macros.sas"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStringForComments() includes = %q, want %q", got, want)
+	}
+}
+
+func TestReadProjectFromDirectoryKeepsGoing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gommentary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "good.sas"), []byte("/** @note Readable */"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Symlink(filepath.Join(dir, "missing"), filepath.Join(dir, "broken.sas"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := ReadProjectFromDirectory(dir, []string{".sas"})
+	list, ok := err.(model.ErrorList)
+	if !ok || len(list) != 1 || list[0].Path != filepath.Join(dir, "broken.sas") {
+		t.Fatalf("ReadProjectFromDirectory() error = %v, want the broken file only", err)
+	}
+	if len(project.Comments) != 1 || project.Comments[0].Filename != filepath.Join(dir, "good.sas") {
+		t.Errorf("ReadProjectFromDirectory() comments = %v, want that of the readable file", project.Comments)
+	}
+}
+
+// panickingFrontend ... a front-end whose parser fails on any code it is given
+type panickingFrontend struct{}
+
+func (panickingFrontend) Name() string         { return "panicking" }
+func (panickingFrontend) Extensions() []string { return []string{".panic"} }
+func (panickingFrontend) Aliases() []string    { return nil }
+func (panickingFrontend) ScanComments(contents string) ([]model.Dependency, []model.Comment, error) {
+	var comments []model.Comment
+	return nil, comments[:1], nil
+}
+func (panickingFrontend) ExtractDependencies(contents string) []model.Dependency                  { return nil }
+func (panickingFrontend) ExtractDefinitions(path string, contents string, project *model.Project) {}
+
+func TestReadProjectFromDirectoryRecoversParserPanics(t *testing.T) {
+	defer func(registered []LanguageFrontend) { frontends = registered }(Frontends())
+	RegisterFrontend(panickingFrontend{})
+
+	dir, err := ioutil.TempDir("", "gommentary")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "good.sas"), []byte("/** @note Readable */"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "bad.panic"), []byte("anything"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := ReadProjectFromDirectory(dir, []string{".sas", ".panic"})
+	list, ok := err.(model.ErrorList)
+	if !ok || len(list) != 1 || list[0].Path != filepath.Join(dir, "bad.panic") {
+		t.Fatalf("ReadProjectFromDirectory() error = %v, want the panicking file only", err)
+	}
+	if len(project.Comments) != 1 || project.Comments[0].Filename != filepath.Join(dir, "good.sas") {
+		t.Errorf("ReadProjectFromDirectory() comments = %v, want that of the readable file", project.Comments)
+	}
+}
+
+func TestRecoverParser(t *testing.T) {
+	parse := func() (err error) {
+		defer recoverParser("bad.sas", &err)
+		var comments []model.Comment
+		_ = comments[:1]
+		return nil
+	}
+	err := parse()
+	e, ok := err.(*model.Error)
+	if !ok || e.Path != "bad.sas" {
+		t.Fatalf("recoverParser() error = %v, want an error of bad.sas", err)
+	}
+	if !strings.Contains(err.Error(), "Parsing failed unexpectedly") {
+		t.Errorf("recoverParser() error = %v", err)
+	}
+}
+
+func TestFinish(t *testing.T) {
+	files := map[string]string{
+		"lib/macros.sas": "%macro clean(ds);\n%mend;\n",
+		"main.sas":       "%include 'macros.sas';\n%include 'other.sas';\n/** @var age :type num Age at entry */\ndata cohort;\nset raw;\nrun;\n",
+	}
+	project := model.Project{}
+	for _, path := range []string{"lib/macros.sas", "main.sas"} {
+		parsed, err := (&Parser{}).Parse(strings.NewReader(files[path]), path)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		project.Add(parsed)
+	}
+	Finish(&project)
+
+	kinds := make([]string, 0)
+	for _, incl := range project.Includes {
+		kinds = append(kinds, incl.Path+" "+incl.Kind)
+	}
+	if want := []string{"macros.sas " + model.DependencyMacro, "other.sas " + model.DependencyScript}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Finish() includes = %q, want %q", kinds, want)
+	}
+	if len(project.Variables) != 1 || project.Variables[0].Name != "age" || project.Variables[0].Dataset != "cohort" {
+		t.Errorf("Finish() variables = %+v", project.Variables)
+	}
+}
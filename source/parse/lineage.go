@@ -0,0 +1,157 @@
+/*
+ * Functions for reading the datasets each step of a program reads and writes
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches the equals sign of an option, along with any surrounding whitespace
+	optionEqualsRegex = regexp.MustCompile("\\s*=\\s*")
+
+	// matches the options of a PROC statement that name a dataset or file being read
+	procInputRegex = regexp.MustCompile("(?i)\\b(data|datafile|infile)=(\"[^\"]*\"|'[^']*'|[^\\s()]+)")
+
+	// matches the options of a PROC statement that name a dataset or file being written
+	procOutputRegex = regexp.MustCompile("(?i)\\b(out|outfile|outest|outstat|outsurv|outexpect)=(\"[^\"]*\"|'[^']*'|[^\\s()]+)")
+
+	// matches the tables created or inserted into by PROC SQL
+	sqlOutputRegex = regexp.MustCompile("(?i)\\b(?:create\\s+(?:table|view)|insert\\s+into)\\s+([a-zA-Z_&][a-zA-Z0-9_&.]*)")
+
+	// matches the tables listed after a FROM or JOIN clause of PROC SQL
+	sqlInputRegex = regexp.MustCompile("(?i)\\b(?:from|join)\\s+([^;(]+?)(?:\\b(?:where|group|order|having|on|union|except|intersect|inner|left|right|full|cross|natural|join|outer)\\b|\\)|$)")
+
+	// datasets that are not really datasets
+	specialDatasets = map[string]bool{"_null_": true, "_data_": true, "_last_": true}
+)
+
+// ParseStringForSASLineage ... obtain the datasets each DATA step and PROC of a SAS string reads and writes
+func ParseStringForSASLineage(contents string) []model.DatasetStep {
+
+	steps := make([]model.DatasetStep, 0)
+	code := StripSASComments(contents)
+
+	// the step currently being read, if any, along with the line of the
+	// statement last read
+	var step *model.DatasetStep
+	lastLine := 0
+	finishStep := func() {
+		if step != nil && (len(step.Inputs) > 0 || len(step.Outputs) > 0) {
+			step.EndLineNum = lastLine
+			steps = append(steps, *step)
+		}
+		step = nil
+	}
+
+	for _, statement := range SplitSASStatements(code) {
+
+		lineNum := LineNumberAt(code, statement.Offset)
+		text := optionEqualsRegex.ReplaceAllString(strings.Join(strings.Fields(statement.Text), " "), "=")
+		fields := strings.Fields(strings.ToLower(text))
+		if len(fields) < 1 {
+			continue
+		}
+		keyword := fields[0]
+
+		switch {
+
+		// the start of a new DATA step
+		case keyword == "data":
+			finishStep()
+			step = &model.DatasetStep{LineNum: lineNum, Step: "data"}
+			step.Outputs = datasetList(text[len(keyword):])
+
+		// the start of a new PROC step
+		case keyword == "proc" && len(fields) > 1:
+			finishStep()
+			step = &model.DatasetStep{LineNum: lineNum, Step: "proc " + fields[1]}
+			addProcDatasets(step, text)
+
+		// the end of the current step
+		case keyword == "run" || keyword == "quit":
+			if step != nil && (keyword == "quit" || step.Step != "proc sql") {
+				lastLine = lineNum
+				finishStep()
+			}
+
+		case step == nil:
+			continue
+
+		// datasets read by a DATA step
+		case step.Step == "data" && (keyword == "set" || keyword == "merge" || keyword == "update" || keyword == "modify"):
+			step.Inputs = model.AppendUnique(step.Inputs, datasetList(text[len(keyword):])...)
+
+		// tables read and written by PROC SQL
+		case step.Step == "proc sql":
+			for _, match := range sqlOutputRegex.FindAllStringSubmatch(text, -1) {
+				step.Outputs = model.AppendUnique(step.Outputs, match[1])
+			}
+			for _, match := range sqlInputRegex.FindAllStringSubmatch(text, -1) {
+				for _, table := range strings.Split(match[1], ",") {
+					if fields := strings.Fields(table); len(fields) > 0 {
+						step.Inputs = model.AppendUnique(step.Inputs, fields[0])
+					}
+				}
+			}
+
+		// any other statement of a PROC, e.g. |output out=stats|
+		case strings.HasPrefix(step.Step, "proc "):
+			addProcDatasets(step, text)
+		}
+
+		lastLine = lineNum
+	}
+	finishStep()
+
+	return steps
+}
+
+// addProcDatasets ... append the datasets named by the data= and out= style options of a PROC statement
+func addProcDatasets(step *model.DatasetStep, text string) {
+	for _, match := range procInputRegex.FindAllStringSubmatch(text, -1) {
+		step.Inputs = model.AppendUnique(step.Inputs, strings.Trim(match[2], "\"'"))
+	}
+	for _, match := range procOutputRegex.FindAllStringSubmatch(text, -1) {
+		step.Outputs = model.AppendUnique(step.Outputs, strings.Trim(match[2], "\"'"))
+	}
+}
+
+// datasetList ... obtain the dataset names from a |set a(keep=x) b end=eof| style list
+func datasetList(text string) []string {
+
+	datasets := make([]string, 0)
+
+	// remove any dataset options given in parenthesis
+	depth := 0
+	plain := ""
+	for _, c := range text {
+		switch {
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0:
+			plain += string(c)
+		}
+	}
+
+	// anything after a slash is an option of the statement itself
+	if slash := strings.Index(plain, "/"); slash != -1 {
+		plain = plain[:slash]
+	}
+
+	for _, field := range strings.Fields(plain) {
+		if strings.Contains(field, "=") || specialDatasets[strings.ToLower(field)] {
+			continue
+		}
+		datasets = model.AppendUnique(datasets, strings.Trim(field, "\"'"))
+	}
+
+	return datasets
+}
@@ -0,0 +1,42 @@
+package parse
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseStringForSASLineage(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"data step", "data work.cohort(keep=id) flagged;\n  set raw.registry(where=(age > 18)) raw.extra end=eof;\nrun;\n",
+			[]string{"1-3 data raw.registry,raw.extra > work.cohort,flagged"}},
+		{"merge", "data both;\n  merge a(in=ina) b;\n  by id;\nrun;\n", []string{"1-4 data a,b > both"}},
+		{"null data step", "data _null_;\n  set cohort;\n  put id;\nrun;\n", []string{"1-4 data cohort > "}},
+		{"proc options", "proc sort data = cohort out=sorted nodupkey;\n  by id;\nrun;\n", []string{"1-3 proc sort cohort > sorted"}},
+		{"output statement", "proc means data=cohort noprint;\n  output out=stats mean=;\nrun;\n", []string{"1-3 proc means cohort > stats"}},
+		{"import", "proc import datafile=\"raw/visits.csv\" out=visits dbms=csv;\nrun;\n",
+			[]string{"1-2 proc import raw/visits.csv > visits"}},
+		{"proc sql", "proc sql;\n  create table summary as\n  select * from cohort c inner join visits v on c.id = v.id;\nrun;\nquit;\n",
+			[]string{"1-5 proc sql cohort,visits > summary"}},
+		{"no datasets", "proc options;\nrun;\n", []string{}},
+		{"unterminated step", "data last;\n  set cohort;\n", []string{"1-2 data cohort > last"}},
+		{"commented out", "/* data gone; set cohort; run; */\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, step := range ParseStringForSASLineage(tt.contents) {
+				got = append(got, strconv.Itoa(step.LineNum)+"-"+strconv.Itoa(step.EndLineNum)+" "+step.Step+" "+
+					strings.Join(step.Inputs, ",")+" > "+strings.Join(step.Outputs, ","))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForSASLineage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+/*
+ * Functions for reading SAS macro definitions and their documentation
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches the start of a |%macro name| definition
+	macroDefinitionRegex = regexp.MustCompile("(?i)%macro\\s+([a-zA-Z_][a-zA-Z0-9_]*)")
+
+	// matches the |%mend| statement that closes a macro definition
+	macroEndRegex = regexp.MustCompile("(?i)%mend\\b[^;]*;")
+
+	// matches block comments that may document a macro
+	blockCommentRegex = regexp.MustCompile("(?s)/\\*.*?\\*/")
+
+	// matches a |# Parameters| style markdown heading
+	parametersHeadingRegex = regexp.MustCompile("(?i)^#+\\s*param(eter)?s?\\s*:?\\s*$")
+
+	// matches any markdown heading
+	headingRegex = regexp.MustCompile("^#+\\s")
+
+	// matches a |- name = Description| style list item
+	paramListItemRegex = regexp.MustCompile("^[-*+]\\s+&?([a-zA-Z_][a-zA-Z0-9_]*)\\s*(=|:|-)?\\s*(.*)$")
+
+	// matches a |@param name Description| style tag
+	paramTagRegex = regexp.MustCompile("@param\\s+&?([a-zA-Z_][a-zA-Z0-9_]*)\\s*=?\\s*([^@;]*)")
+)
+
+// ParseStringForMacros ... obtain all of the macro definitions from a given string
+func ParseStringForMacros(filename string, contents string) []model.Macro {
+
+	macros := make([]model.Macro, 0)
+	code := StripSASComments(contents)
+
+	// the documentation of a macro may appear anywhere between the end of
+	// the previous macro and the start of the given one
+	docsStart := 0
+
+	for _, sindex := range macroDefinitionRegex.FindAllStringSubmatchIndex(code, -1) {
+
+		// skip definitions nested inside of the previous macro
+		if sindex[0] < docsStart {
+			continue
+		}
+
+		macro := model.Macro{
+			Name:     code[sindex[2]:sindex[3]],
+			Filename: filename,
+			LineNum:  LineNumberAt(code, sindex[0]),
+		}
+
+		// obtain the parameter list, if any
+		rest := code[sindex[1]:]
+		trimmed := strings.TrimLeft(rest, " \t\r\n")
+		if strings.HasPrefix(trimmed, "(") {
+			end := matchingParen(trimmed)
+			if end != -1 {
+				macro.Params = parseMacroParams(trimmed[1:end])
+			}
+		}
+
+		// find the end of the macro, defaulting to the end of the file
+		end := len(code)
+		if mend := macroEndRegex.FindStringIndex(rest); mend != nil {
+			end = sindex[1] + mend[1]
+		}
+		macro.EndLineNum = LineNumberAt(code, end)
+
+		macro.Summary, macro.DocumentedParams = parseMacroDocumentation(contents, docsStart, sindex[0])
+
+		macros = append(macros, macro)
+		docsStart = end
+	}
+
+	return macros
+}
+
+// matchingParen ... obtain the index of the parenthesis closing the one that starts the string
+func matchingParen(str string) int {
+	depth := 0
+	for i, c := range str {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// splitTopLevel ... split a string by a separator, ignoring separators inside of parenthesis or quotes
+func splitTopLevel(str string, sep rune) []string {
+	pieces := make([]string, 0)
+	depth := 0
+	var quote rune
+	start := 0
+	for i, c := range str {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == sep && depth == 0:
+			pieces = append(pieces, str[start:i])
+			start = i + 1
+		}
+	}
+	return append(pieces, str[start:])
+}
+
+// parseMacroParams ... convert the text between the parenthesis of a %macro statement into parameters
+func parseMacroParams(text string) []model.MacroParam {
+	params := make([]model.MacroParam, 0)
+	for _, piece := range splitTopLevel(text, ',') {
+		piece = strings.TrimSpace(piece)
+		if piece == "" {
+			continue
+		}
+		param := model.MacroParam{Name: piece}
+		if eq := strings.Index(piece, "="); eq != -1 {
+			param.Name = strings.TrimSpace(piece[:eq])
+			param.Default = strings.TrimSpace(piece[eq+1:])
+			param.Keyword = true
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// parseMacroDocumentation ... obtain the summary and documented parameters from the comments in [start, end)
+func parseMacroDocumentation(contents string, start int, end int) (string, []model.MacroParam) {
+
+	summary := ""
+	params := make([]model.MacroParam, 0)
+	region := contents[start:end]
+
+	for _, sindex := range blockCommentRegex.FindAllStringIndex(region, -1) {
+
+		block := region[sindex[0]:sindex[1]]
+		lineNum := LineNumberAt(contents, start+sindex[0])
+		inParameters := false
+		inSummary := false
+
+		for i, line := range strings.Split(block, "\n") {
+
+			// trim away the comment delimiters
+			line = strings.TrimSpace(line)
+			line = strings.TrimPrefix(line, "/*~")
+			line = strings.TrimPrefix(line, "/**")
+			line = strings.TrimPrefix(line, "/*")
+			line = strings.TrimSuffix(line, "~*/")
+			line = strings.TrimSuffix(line, "*/")
+			line = strings.TrimSpace(line)
+
+			// handle the |@param name Description| tags
+			for _, match := range paramTagRegex.FindAllStringSubmatch(line, -1) {
+				params = append(params, model.MacroParam{
+					Name:        match[1],
+					Description: strings.TrimSpace(match[2]),
+					LineNum:     lineNum + i,
+				})
+			}
+
+			switch {
+			case parametersHeadingRegex.MatchString(line):
+				inParameters, inSummary = true, false
+				continue
+			case headingRegex.MatchString(line):
+				inParameters = false
+				inSummary = strings.EqualFold(strings.TrimSpace(strings.TrimLeft(line, "#")), "summary")
+				continue
+			}
+
+			// handle the |- name = Description| items of a parameters list
+			if inParameters {
+				match := paramListItemRegex.FindStringSubmatch(line)
+				if match != nil {
+					params = append(params, model.MacroParam{
+						Name:        match[1],
+						Description: strings.TrimSpace(match[3]),
+						LineNum:     lineNum + i,
+					})
+				}
+				continue
+			}
+
+			if inSummary && line != "" {
+				summary = strings.TrimSpace(summary + " " + line)
+			}
+		}
+	}
+
+	return summary, params
+}
@@ -0,0 +1,84 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestParseStringForMacros(t *testing.T) {
+	contents := "/*~\n" +
+		"# Summary\n" +
+		"Counts the rows\n" +
+		"of a dataset\n" +
+		"\n" +
+		"# Parameters\n" +
+		"- ds = Dataset to count\n" +
+		"- &out: Macro variable to set\n" +
+		"~*/\n" +
+		"%macro count(ds, out=n, where=%str(a, b));\n" +
+		"  /* %mend; */\n" +
+		"  proc sql noprint; select count(*) into :&out from &ds; quit;\n" +
+		"%mend count;\n" +
+		"/* @param x The value @param y Another */\n" +
+		"%MACRO twice(x);\n" +
+		"  %eval(2 * &x)"
+	macros := ParseStringForMacros("a.sas", contents)
+	if len(macros) != 2 {
+		t.Fatalf("ParseStringForMacros() = %d macros, want 2", len(macros))
+	}
+
+	count := macros[0]
+	if count.Name != "count" || count.Filename != "a.sas" || count.LineNum != 10 || count.EndLineNum != 13 ||
+		count.Summary != "Counts the rows of a dataset" {
+		t.Errorf("ParseStringForMacros() = %+v, want count on lines 10 to 13 with its summary", count)
+	}
+	wantParams := []model.MacroParam{
+		{Name: "ds"},
+		{Name: "out", Default: "n", Keyword: true},
+		{Name: "where", Default: "%str(a, b)", Keyword: true},
+	}
+	if !reflect.DeepEqual(count.Params, wantParams) {
+		t.Errorf("ParseStringForMacros() params = %+v, want %+v", count.Params, wantParams)
+	}
+	wantDocumented := []model.MacroParam{
+		{Name: "ds", Description: "Dataset to count", LineNum: 7},
+		{Name: "out", Description: "Macro variable to set", LineNum: 8},
+	}
+	if !reflect.DeepEqual(count.DocumentedParams, wantDocumented) {
+		t.Errorf("ParseStringForMacros() documented = %+v, want %+v", count.DocumentedParams, wantDocumented)
+	}
+
+	twice := macros[1]
+	if twice.Name != "twice" || twice.LineNum != 15 || twice.EndLineNum != 16 || twice.Summary != "" {
+		t.Errorf("ParseStringForMacros() = %+v, want twice running from line 15 to the end", twice)
+	}
+	wantDocumented = []model.MacroParam{
+		{Name: "x", Description: "The value", LineNum: 14},
+		{Name: "y", Description: "Another", LineNum: 14},
+	}
+	if !reflect.DeepEqual(twice.DocumentedParams, wantDocumented) {
+		t.Errorf("ParseStringForMacros() documented = %+v, want %+v", twice.DocumentedParams, wantDocumented)
+	}
+}
+
+func TestParseMacroParams(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []model.MacroParam
+	}{
+		{"empty", " ", []model.MacroParam{}},
+		{"positional", "a, b", []model.MacroParam{{Name: "a"}, {Name: "b"}}},
+		{"keyword", "a=1, b=", []model.MacroParam{{Name: "a", Default: "1", Keyword: true}, {Name: "b", Keyword: true}}},
+		{"quoted comma", "sep=',', x", []model.MacroParam{{Name: "sep", Default: "','", Keyword: true}, {Name: "x"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseMacroParams(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseMacroParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+/*
+ * Functions for reading the macro variables assigned via %let and %global
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches a |%let name = value| statement, including one following |%then| or |%do;|
+	letStatementRegex = regexp.MustCompile("(?is)(?:^|\\s)%let\\s+([a-zA-Z_][a-zA-Z0-9_]*)\\s*=(.*)$")
+
+	// matches a |%global name ...| or |%local name ...| statement
+	scopeStatementRegex = regexp.MustCompile("(?is)^%(global|local)\\s+(.*)$")
+
+	// options of the |%global| and |%local| statements, which are not variables
+	scopeOptions = map[string]bool{"/": true, "readonly": true, "nowarn": true}
+)
+
+// ParseStringForMacroVariables ... obtain the macro variables assigned or declared global in a SAS string
+//
+// Assignments made inside of a macro to one of its parameters, or to a
+// variable it declares via |%local|, are marked as local since they never
+// affect the configuration of the project as a whole.
+func ParseStringForMacroVariables(filename string, contents string, macros []model.Macro) []model.MacroVariable {
+
+	variables := make([]model.MacroVariable, 0)
+	code := StripSASComments(contents)
+
+	// names of the local and global variables of each macro in the file
+	locals := make(map[string]map[string]bool)
+	globals := make(map[string]map[string]bool)
+	for _, macro := range macros {
+		if macro.Filename != filename {
+			continue
+		}
+		locals[macro.Name] = make(map[string]bool)
+		globals[macro.Name] = make(map[string]bool)
+		for _, param := range macro.Params {
+			locals[macro.Name][strings.ToLower(param.Name)] = true
+		}
+	}
+
+	for _, statement := range SplitSASStatements(code) {
+
+		text := strings.TrimSpace(statement.Text)
+		lineNum := LineNumberAt(code, statement.Offset)
+
+		// attribute the statement to the macro it appears inside of, if any
+		enclosing := ""
+		for _, macro := range macros {
+			if macro.Filename == filename && macro.LineNum <= lineNum && lineNum <= macro.EndLineNum {
+				enclosing = macro.Name
+			}
+		}
+
+		if match := scopeStatementRegex.FindStringSubmatch(text); match != nil {
+			for _, field := range strings.Fields(match[2]) {
+				pieces := strings.SplitN(field, "=", 2)
+				name := strings.ToLower(pieces[0])
+				if name == "" || scopeOptions[name] {
+					continue
+				}
+				if enclosing != "" && strings.ToLower(match[1]) == "local" {
+					locals[enclosing][name] = true
+					continue
+				}
+				if enclosing != "" {
+					globals[enclosing][name] = true
+				}
+				if strings.ToLower(match[1]) == "global" {
+					variable := model.MacroVariable{
+						Name:      pieces[0],
+						Statement: "%global",
+						Filename:  filename,
+						LineNum:   lineNum,
+						Macro:     enclosing,
+						Comment:   AdjacentComment(contents, lineNum),
+					}
+					if len(pieces) > 1 {
+						variable.Value = pieces[1]
+					}
+					variables = append(variables, variable)
+				}
+			}
+			continue
+		}
+
+		sindex := letStatementRegex.FindStringSubmatchIndex(text)
+		if sindex == nil {
+			continue
+		}
+		name := text[sindex[2]:sindex[3]]
+		lineNum = LineNumberAt(code, statement.Offset+sindex[2])
+
+		variable := model.MacroVariable{
+			Name:      name,
+			Statement: "%let",
+			Filename:  filename,
+			LineNum:   lineNum,
+			Value:     strings.Join(strings.Fields(text[sindex[4]:sindex[5]]), " "),
+			Macro:     enclosing,
+			Comment:   AdjacentComment(contents, lineNum),
+		}
+		if enclosing != "" {
+			lowercase := strings.ToLower(name)
+			variable.Local = locals[enclosing][lowercase] && !globals[enclosing][lowercase]
+		}
+		variables = append(variables, variable)
+	}
+
+	return variables
+}
+
+// ParseStringForMacroVariableReferences ... obtain all of the |&name| references from a given SAS string
+func ParseStringForMacroVariableReferences(filename string, contents string) []model.Reference {
+
+	references := make([]model.Reference, 0)
+	code := StripSASComments(contents)
+
+	for _, sindex := range macroVariableRegex.FindAllStringSubmatchIndex(code, -1) {
+		references = append(references, model.Reference{
+			Name:     code[sindex[2]:sindex[3]],
+			Filename: filename,
+			LineNum:  LineNumberAt(code, sindex[0]),
+		})
+	}
+
+	return references
+}
@@ -0,0 +1,56 @@
+package parse
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParseStringForMacroVariables(t *testing.T) {
+	contents := "%let root = /proj ; /* Project root */\n" +
+		"%global debug env=prod;\n" +
+		"%macro setup(out=);\n" +
+		"  %local i;\n" +
+		"  %global n;\n" +
+		"  %let out = x;\n" +
+		"  %let i = 1;\n" +
+		"  %let n = 2;\n" +
+		"  %if &debug %then %let level = 3;\n" +
+		"%mend;\n" +
+		"%let cutoff = 5; * Minimum count;\n" +
+		"%global flag; * Set by the caller;\n" +
+		"%let total = 5 * 2;\n"
+	macros := ParseStringForMacros("a.sas", contents)
+	got := make([]string, 0)
+	for _, variable := range ParseStringForMacroVariables("a.sas", contents, macros) {
+		got = append(got, strconv.Itoa(variable.LineNum)+" "+variable.Statement+" "+variable.Name+"="+variable.Value+
+			" "+variable.Macro+" "+strconv.FormatBool(variable.Local)+" "+variable.Comment)
+	}
+	want := []string{
+		"1 %let root=/proj  false Project root",
+		"2 %global debug=  false ",
+		"2 %global env=prod  false ",
+		"5 %global n= setup false ",
+		"6 %let out=x setup true ",
+		"7 %let i=1 setup true ",
+		"8 %let n=2 setup false ",
+		"9 %let level=3 setup false ",
+		"11 %let cutoff=5  false Minimum count",
+		"12 %global flag=  false Set by the caller",
+		"13 %let total=5 * 2  false ",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStringForMacroVariables() = %q, want %q", got, want)
+	}
+}
+
+func TestParseStringForMacroVariableReferences(t *testing.T) {
+	contents := "title \"&study. report\";\n/* &commented */\ndata &&lib&i..x; run;\n"
+	got := make([]string, 0)
+	for _, reference := range ParseStringForMacroVariableReferences("a.sas", contents) {
+		got = append(got, strconv.Itoa(reference.LineNum)+" "+reference.Name)
+	}
+	if want := []string{"1 study", "3 lib", "3 i"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStringForMacroVariableReferences() = %q, want %q", got, want)
+	}
+}
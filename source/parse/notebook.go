@@ -0,0 +1,139 @@
+/*
+ * Functions for splitting Jupyter notebooks into their cells
+ */
+
+package parse
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// Cell object definition
+type Cell struct {
+
+	// kind of the cell, i.e. |code|, |markdown| or |raw|
+	Kind string
+
+	// number of the cell, counting from one at the top of the notebook
+	Number int
+
+	// source text of the cell
+	Source string
+}
+
+// Notebook object definition
+type Notebook struct {
+
+	// language of the kernel of the notebook, e.g. |python| or |sas|
+	Language string
+
+	// cells of the notebook, in order
+	Cells []Cell
+}
+
+// notebookJSON ... the parts of the nbformat 4 JSON of a notebook that are read
+type notebookJSON struct {
+	Cells []struct {
+		CellType string          `json:"cell_type"`
+		Source   json.RawMessage `json:"source"`
+	} `json:"cells"`
+	Metadata struct {
+		Kernelspec struct {
+			Name     string `json:"name"`
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+}
+
+// ParseStringForNotebook ... obtain the kernel language and the cells of a given Jupyter notebook string
+//
+// The language is that of the kernelspec of the notebook, falling back to
+// its language_info and then to the name of the kernel. The source of a cell
+// is given either as a single string or as a list of lines.
+func ParseStringForNotebook(contents string) (Notebook, error) {
+
+	notebook := Notebook{Cells: make([]Cell, 0)}
+
+	var document notebookJSON
+	if err := json.Unmarshal([]byte(contents), &document); err != nil {
+		return notebook, err
+	}
+
+	for _, language := range []string{document.Metadata.Kernelspec.Language, document.Metadata.LanguageInfo.Name,
+		document.Metadata.Kernelspec.Name} {
+		if language != "" {
+			notebook.Language = strings.ToLower(language)
+			break
+		}
+	}
+
+	for i, cell := range document.Cells {
+		source := ""
+		if err := json.Unmarshal(cell.Source, &source); err != nil {
+			lines := make([]string, 0)
+			if err := json.Unmarshal(cell.Source, &lines); err != nil && len(cell.Source) > 0 {
+				return notebook, err
+			}
+			source = strings.Join(lines, "")
+		}
+		notebook.Cells = append(notebook.Cells, Cell{Kind: cell.CellType, Number: i + 1, Source: source})
+	}
+
+	return notebook, nil
+}
+
+// notebookKernel ... obtain the front-end of the kernel language of a notebook, or nil if there is none
+func notebookKernel(notebook Notebook) LanguageFrontend {
+	frontend := Frontend(notebook.Language)
+	if frontend == nil || frontend.Name() == model.LanguageJupyter {
+		return nil
+	}
+	return frontend
+}
+
+// cellPart ... obtain the name of a cell as the part of the notebook it is, e.g. |cell 3|
+func cellPart(cell Cell) string {
+	return "cell " + strconv.Itoa(cell.Number)
+}
+
+// ParseStringForMarkdownNarrative ... obtain each paragraph of a given markdown string as a comment
+//
+// A paragraph starting with an |@keyword| is filed under that keyword, as is
+// any other comment, and every paragraph is part of the narrative of the
+// document. The |#| markers of headings are dropped.
+func ParseStringForMarkdownNarrative(contents string) []model.Comment {
+
+	comments := make([]model.Comment, 0)
+	lines := strings.Split(contents, "\n")
+
+	for i := 0; i < len(lines); i++ {
+
+		if strings.TrimSpace(lines[i]) == "" {
+			continue
+		}
+
+		// the paragraph runs up to the next blank line
+		paragraph := make([]string, 0)
+		lineNum := i + 1
+		for ; i < len(lines) && strings.TrimSpace(lines[i]) != ""; i++ {
+			paragraph = append(paragraph, strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(lines[i]), "#")))
+		}
+
+		text := strings.Join(paragraph, " ")
+		keyword := leadingKeywordRegex.FindString(text)
+		newComment := model.Comment{LineNum: lineNum, Text: strings.TrimSpace(text[len(keyword):]), Narrative: true}
+		if keyword != "" {
+			newComment.Keyword = strings.TrimSpace(keyword) + " "
+		}
+		comments = append(comments, newComment)
+	}
+
+	return comments
+}
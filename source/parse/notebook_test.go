@@ -0,0 +1,96 @@
+package parse
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestParseStringForNotebook(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     Notebook
+		wantErr  bool
+	}{
+		{"kernelspec language", `{"cells": [{"cell_type": "code", "source": ["x = 1\n", "y = 2"]}, {"cell_type": "markdown", "source": "# Title"}],
+			"metadata": {"kernelspec": {"name": "python3", "language": "python"}}}`,
+			Notebook{Language: "python", Cells: []Cell{{Kind: "code", Number: 1, Source: "x = 1\ny = 2"}, {Kind: "markdown", Number: 2, Source: "# Title"}}}, false},
+		{"language info", `{"cells": [], "metadata": {"language_info": {"name": "SAS"}}}`, Notebook{Language: "sas", Cells: []Cell{}}, false},
+		{"kernel name", `{"cells": [{"cell_type": "raw"}], "metadata": {"kernelspec": {"name": "stata"}}}`,
+			Notebook{Language: "stata", Cells: []Cell{{Kind: "raw", Number: 1}}}, false},
+		{"not json", "data a; run;", Notebook{Cells: []Cell{}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseStringForNotebook(tt.contents)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseStringForNotebook() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForNotebook() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringForMarkdownNarrative(t *testing.T) {
+	contents := "## Cohort selection\n\n@note Only adults,\nas agreed\n\n\nPlain text\n"
+	got := make([]string, 0)
+	for _, cmt := range ParseStringForMarkdownNarrative(contents) {
+		got = append(got, strconv.Itoa(cmt.LineNum)+" "+cmt.Keyword+"|"+cmt.Text)
+		if !cmt.Narrative {
+			t.Errorf("ParseStringForMarkdownNarrative() comment %q is not narrative", cmt.Text)
+		}
+	}
+	want := []string{"1 |Cohort selection", "3 @note |Only adults, as agreed", "7 |Plain text"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStringForMarkdownNarrative() = %q, want %q", got, want)
+	}
+}
+
+func TestParseNotebook(t *testing.T) {
+	contents := `{
+ "cells": [
+  {"cell_type": "markdown", "source": ["@note Exploring the visits"]},
+  {"cell_type": "code", "source": ["data cohort;\n", "  set raw.visits;\n", "run;\n", "/**@note In SAS*/\n", "%include 'setup.sas';"]},
+  {"cell_type": "code", "source": "%macro m;\n%mend;"}
+ ],
+ "metadata": {"kernelspec": {"display_name": "SAS", "language": "sas", "name": "sas"}},
+ "nbformat": 4
+}`
+	project, err := (&Parser{}).Parse(strings.NewReader(contents), "explore.ipynb")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := make([]string, 0)
+	for _, cmt := range project.Comments {
+		got = append(got, cmt.Part+":"+strconv.Itoa(cmt.LineNum)+" "+cmt.Text)
+	}
+	for _, incl := range project.Includes {
+		got = append(got, incl.Filename+":"+strconv.Itoa(incl.LineNum)+" "+incl.Path)
+	}
+	for _, step := range project.Steps {
+		got = append(got, step.Filename+":"+strconv.Itoa(step.LineNum)+" "+step.Step)
+	}
+	for _, macro := range project.Macros {
+		got = append(got, macro.Filename+":"+strconv.Itoa(macro.LineNum)+" "+macro.Name)
+	}
+	want := []string{
+		"cell 1:1 Exploring the visits",
+		"cell 2:4 In SAS",
+		"explore.ipynb › cell 2:5 setup.sas",
+		"explore.ipynb › cell 2:1 data",
+		"explore.ipynb › cell 3:1 m",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %q, want %q", got, want)
+	}
+	if project.Languages["explore.ipynb"] != model.LanguageJupyter {
+		t.Errorf("Parse() language = %q", project.Languages["explore.ipynb"])
+	}
+}
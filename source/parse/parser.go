@@ -0,0 +1,102 @@
+/*
+ * The parser of a single file of code, for use by this and other programs
+ */
+
+package parse
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// Parser ... parser of the comments, dependencies and definitions of code in a given language
+type Parser struct {
+
+	// language of the code, e.g. |model.LanguageSAS|; if blank it is detected from each file
+	Language string
+}
+
+// NewParser ... create a parser of code in the given language, which must have a registered front-end
+func NewParser(language string) (*Parser, error) {
+	frontend := Frontend(language)
+	if frontend == nil {
+		return nil, fmt.Errorf("Unsupported language: %s", language)
+	}
+	return &Parser{Language: frontend.Name()}, nil
+}
+
+// LanguageOf ... obtain the language of a file from its extension, or blank if it is not supported
+func LanguageOf(path string) string {
+	if frontend := FrontendForExtension(path); frontend != nil {
+		return frontend.Name()
+	}
+	return ""
+}
+
+// Parse ... read the code of a single file and obtain its comments, dependencies and definitions
+//
+// The path is only recorded as the location of whatever is found, the file
+// itself is never opened, so the code may just as well come from memory. The
+// project returned holds just that file, ready to be added to a larger one.
+// Code in a language without a front-end is only parsed for its comments.
+func (p *Parser) Parse(r io.Reader, path string) (model.Project, error) {
+
+	project := model.Project{Files: []string{path}, Languages: map[string]string{}}
+
+	bytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return project, model.NewError(path, 0, err)
+	}
+	contents := string(bytes)
+
+	var frontend LanguageFrontend
+	if p.Language != "" {
+		frontend = Frontend(p.Language)
+	} else {
+		frontend = DetectFrontend(path, contents)
+	}
+	if frontend != nil {
+		project.Languages[path] = frontend.Name()
+	}
+
+	// if file is empty, skip it
+	if contents == "" {
+		return project, nil
+	}
+
+	scanComments := ParseStringForComments
+	if frontend != nil {
+		scanComments = frontend.ScanComments
+	}
+	included, parsed, err := scanComments(contents)
+	if err != nil {
+		return project, model.NewError(path, 0, err)
+	}
+
+	// attach filename to includes and append them
+	for _, incl := range included {
+		incl.Filename = model.PartOf(path, incl.Filename)
+		project.Includes = append(project.Includes, incl)
+	}
+
+	// the dependencies and definitions are particular to each language
+	if frontend != nil {
+		for _, incl := range frontend.ExtractDependencies(contents) {
+			incl.Filename = model.PartOf(path, incl.Filename)
+			project.Includes = append(project.Includes, incl)
+		}
+		frontend.ExtractDefinitions(path, contents, &project)
+	}
+
+	// attach filename and index to comments and append them
+	for _, cmt := range parsed {
+		cmt.Filename = path
+		cmt.Index = 1
+		project.Comments = append(project.Comments, cmt)
+	}
+
+	return project, nil
+}
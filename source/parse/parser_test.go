@@ -0,0 +1,72 @@
+package parse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestParserParse(t *testing.T) {
+	tests := []struct {
+		name         string
+		language     string
+		code         string
+		wantComments int
+		wantMacros   int
+		wantPrograms int
+		wantErr      bool
+	}{
+		{"SAS macro", "SAS", "/** @note A macro */\n%macro hello(name);\n%put &name;\n%mend;\n", 1, 1, 0, false},
+		{"Stata program", "stata", "**@note A program;\nprogram define hello\n\tdisplay \"hello\"\nend\n", 1, 0, 1, false},
+		{"empty file", "sas", "", 0, 0, 0, false},
+		{"unsupported language", "cobol", "", 0, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser, err := NewParser(tt.language)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewParser() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			project, err := parser.Parse(strings.NewReader(tt.code), "code/file")
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(project.Files) != 1 || len(project.Comments) != tt.wantComments ||
+				len(project.Macros) != tt.wantMacros || len(project.Programs) != tt.wantPrograms {
+				t.Errorf("Parse() = %d files, %d comments, %d macros, %d programs", len(project.Files),
+					len(project.Comments), len(project.Macros), len(project.Programs))
+			}
+			for _, cmt := range project.Comments {
+				if cmt.Filename != "code/file" || cmt.Index != 1 {
+					t.Errorf("Parse() comment = %v", cmt)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectAdd(t *testing.T) {
+	parser := &Parser{Language: model.LanguageSAS}
+	project := model.Project{}
+	for _, code := range []string{"/** @note First */\n", "data a; run;\n", "/** @note Second */\n/** @note Third */\n"} {
+		parsed, err := parser.Parse(strings.NewReader(code), "file.sas")
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		project.Add(parsed)
+	}
+	// the file without comments is not numbered
+	want := []int{1, 2, 2}
+	if len(project.Files) != 3 || len(project.Comments) != len(want) || len(project.Steps) != 1 {
+		t.Fatalf("Add() = %d files, %d comments, %d steps", len(project.Files), len(project.Comments), len(project.Steps))
+	}
+	for i, cmt := range project.Comments {
+		if cmt.Index != want[i] {
+			t.Errorf("Add() comment %d index = %d, want %d", i, cmt.Index, want[i])
+		}
+	}
+}
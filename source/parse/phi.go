@@ -0,0 +1,154 @@
+/*
+ * Functions for detecting, and optionally redacting, identifiers in the comments of a project
+ */
+
+package parse
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// BuiltinDetectors ... patterns for the common formats of identifiers, checked in order
+var BuiltinDetectors = []model.Detector{
+	{Name: "email address", Pattern: regexp.MustCompile("[a-zA-Z0-9._%+-]+@[a-zA-Z0-9-]+(?:\\.[a-zA-Z0-9-]+)*\\.[a-zA-Z]{2,}")},
+	{Name: "date of birth", Pattern: regexp.MustCompile("(?i)\\b(?:dob|date of birth|birth ?date|born(?: on)?)\\b\\W{0,3}" +
+		"(?:\\d{1,4}[-/.]\\d{1,2}[-/.]\\d{1,4}|\\d{1,2}[a-z]{3}\\d{2,4}|[a-z]+\\.? \\d{1,2},? \\d{4}|\\d{1,2} [a-z]+\\.? \\d{4})")},
+	{Name: "phone number", Pattern: regexp.MustCompile("(?:\\+?1[-. ]?)?(?:\\([2-9]\\d{2}\\) ?|\\b[2-9]\\d{2}[-. ])[2-9]\\d{2}[-. ]\\d{4}\\b")},
+	{Name: "health card number", Pattern: regexp.MustCompile("\\b\\d{4}[- ]?\\d{3}[- ]?\\d{3}(?:[- ]?[A-Z]{2})?\\b|\\b\\d{3}[- ]?\\d{3}[- ]?\\d{3}\\b")},
+	{Name: "postal code", Pattern: regexp.MustCompile("\\b[ABCEGHJ-NPRSTVXY]\\d[ABCEGHJ-NPRSTV-Z][ -]?\\d[ABCEGHJ-NPRSTV-Z]\\d\\b")},
+}
+
+// ReadDetectorsFile ... obtain the custom detectors given as |name = regex| lines of a file
+//
+// Blank lines and lines starting with # are skipped, so that the patterns of
+// a project can be documented alongside them.
+func ReadDetectorsFile(path string) ([]model.Detector, error) {
+
+	detectors := make([]model.Detector, 0)
+
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return detectors, err
+	}
+
+	for i, line := range strings.Split(string(bytes), "\n") {
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		equals := strings.Index(line, "=")
+		if equals < 1 {
+			return detectors, model.NewError(path, i+1, fmt.Errorf("Detector is not of the form name = regex"))
+		}
+		pattern, err := regexp.Compile(strings.TrimSpace(line[equals+1:]))
+		if err != nil {
+			return detectors, model.NewError(path, i+1, err)
+		}
+		detectors = append(detectors, model.Detector{Name: strings.TrimSpace(line[:equals]), Pattern: pattern})
+	}
+
+	return detectors, nil
+}
+
+// phiScanner ... state gathered while checking the text of a project for identifiers
+type phiScanner struct {
+
+	// detectors to check the text with, in order
+	detectors []model.Detector
+
+	// whether identifiers are replaced in the text, rather than only flagged
+	redact bool
+
+	// identifiers found so far
+	findings []model.Finding
+
+	// most identifiers found in one text for each detector, file and line, as
+	// the same comment is often kept in more than one place of a project
+	reported map[model.Finding]int
+}
+
+// scan ... check a piece of text that starts on the given line for identifiers
+//
+// The matches of each detector are replaced, in a working copy, before the
+// next detector is checked so that an identifier is only reported once; the
+// copy replaces the text itself only if redacting. Identifiers already
+// reported from another copy of the same comment are not reported again.
+func (s *phiScanner) scan(text *string, filename string, lineNum int) {
+
+	working := *text
+	counts := make(map[model.Finding]int)
+	for _, detector := range s.detectors {
+
+		// all matches are found up front, as a replacement may match again
+		replaced := ""
+		end := 0
+		for _, sindex := range detector.Pattern.FindAllStringIndex(working, -1) {
+			if sindex[0] == sindex[1] {
+				continue
+			}
+			finding := model.Finding{
+				Detector: detector.Name,
+				Filename: filename,
+				LineNum:  lineNum + strings.Count(working[:sindex[0]], "\n"),
+				Redacted: s.redact,
+			}
+			counts[finding]++
+			if counts[finding] > s.reported[finding] {
+				s.reported[finding] = counts[finding]
+				s.findings = append(s.findings, finding)
+			}
+			replaced += working[end:sindex[0]] + "[redacted " + detector.Name + "]"
+			end = sindex[1]
+		}
+		working = replaced + working[end:]
+	}
+
+	if s.redact {
+		*text = working
+	}
+}
+
+// ScanProjectForIdentifiers ... find the identifiers in the comments of a project, redacting them if requested
+//
+// Besides the comments themselves this covers the text taken from comments
+// elsewhere, such as macro documentation and the data dictionary.
+func ScanProjectForIdentifiers(project *model.Project, detectors []model.Detector, redact bool) []model.Finding {
+
+	s := &phiScanner{detectors: detectors, redact: redact, findings: make([]model.Finding, 0),
+		reported: make(map[model.Finding]int)}
+
+	for i := range project.Comments {
+		cmt := &project.Comments[i]
+		s.scan(&cmt.Text, cmt.Filename, cmt.LineNum)
+	}
+	for _, macros := range [][]model.Macro{project.Macros, project.Programs} {
+		for i := range macros {
+			macro := &macros[i]
+			s.scan(&macro.Summary, macro.Filename, macro.LineNum)
+			for j := range macro.DocumentedParams {
+				s.scan(&macro.DocumentedParams[j].Description, macro.Filename, macro.DocumentedParams[j].LineNum)
+			}
+		}
+	}
+	for i := range project.Formats {
+		s.scan(&project.Formats[i].Comment, project.Formats[i].Filename, project.Formats[i].LineNum)
+	}
+	for i := range project.MacroVariables {
+		s.scan(&project.MacroVariables[i].Comment, project.MacroVariables[i].Filename, project.MacroVariables[i].LineNum)
+	}
+	for i := range project.Variables {
+		variable := &project.Variables[i]
+		s.scan(&variable.Label, variable.Filename, variable.LineNum)
+		s.scan(&variable.Description, variable.Filename, variable.LineNum)
+		s.scan(&variable.Codes, variable.Filename, variable.LineNum)
+	}
+
+	return s.findings
+}
@@ -0,0 +1,96 @@
+package parse
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestScanProjectForIdentifiers(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		want      string
+		detectors []string
+	}{
+		{"no identifiers", "Exclude any record before 1960", "Exclude any record before 1960", []string{}},
+		{"email address", "Ask jane.doe@example.org", "Ask [redacted email address]", []string{"email address"}},
+		{"date of birth", "Patient DOB: 1960-01-01 excluded", "Patient [redacted date of birth] excluded", []string{"date of birth"}},
+		{"date of birth as a SAS date", "born 01JAN1960", "[redacted date of birth]", []string{"date of birth"}},
+		{"phone number", "Call (204) 555-0123", "Call [redacted phone number]", []string{"phone number"}},
+		{"health card number", "PHIN 123456789 and 1234-567-890-AB",
+			"PHIN [redacted health card number] and [redacted health card number]",
+			[]string{"health card number", "health card number"}},
+		{"postal code", "Lives in R3T 2N2", "Lives in [redacted postal code]", []string{"postal code"}},
+		{"years and counts are kept", "Records 2015 to 2020, n = 12345", "Records 2015 to 2020, n = 12345", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := model.Project{Comments: []model.Comment{{Filename: "a.sas", LineNum: 3, Text: tt.text}}}
+			findings := ScanProjectForIdentifiers(&project, BuiltinDetectors, true)
+			if project.Comments[0].Text != tt.want {
+				t.Errorf("ScanProjectForIdentifiers() text = %q, want %q", project.Comments[0].Text, tt.want)
+			}
+			detectors := make([]string, 0)
+			for _, finding := range findings {
+				detectors = append(detectors, finding.Detector)
+				if finding.Filename != "a.sas" || finding.LineNum != 3 || !finding.Redacted {
+					t.Errorf("ScanProjectForIdentifiers() finding = %v", finding)
+				}
+			}
+			if !reflect.DeepEqual(detectors, tt.detectors) {
+				t.Errorf("ScanProjectForIdentifiers() detectors = %v, want %v", detectors, tt.detectors)
+			}
+		})
+	}
+}
+
+func TestScanProjectForIdentifiersFlagOnly(t *testing.T) {
+	project := model.Project{Comments: []model.Comment{{Filename: "a.do", LineNum: 10, Text: "Summary\nsent to jane.doe@example.org"}}}
+	findings := ScanProjectForIdentifiers(&project, BuiltinDetectors, false)
+	if project.Comments[0].Text != "Summary\nsent to jane.doe@example.org" {
+		t.Errorf("ScanProjectForIdentifiers() changed the text to %q", project.Comments[0].Text)
+	}
+	if len(findings) != 1 || findings[0].LineNum != 11 || findings[0].Redacted {
+		t.Errorf("ScanProjectForIdentifiers() findings = %v", findings)
+	}
+}
+
+func TestScanProjectForIdentifiersMatchingRedaction(t *testing.T) {
+	detectors := []model.Detector{{Name: "patient id", Pattern: regexp.MustCompile("(?i)patient id\\s*\\d*")}}
+	for _, redact := range []bool{true, false} {
+		t.Run(strconv.FormatBool(redact), func(t *testing.T) {
+			project := model.Project{Comments: []model.Comment{{Filename: "a.sas", LineNum: 1, Text: "Patient ID 123 and patient id 456"}}}
+			findings := ScanProjectForIdentifiers(&project, detectors, redact)
+			if len(findings) != 2 {
+				t.Errorf("ScanProjectForIdentifiers() findings = %v, want two", findings)
+			}
+			want := "Patient ID 123 and patient id 456"
+			if redact {
+				want = "[redacted patient id] and [redacted patient id]"
+			}
+			if project.Comments[0].Text != want {
+				t.Errorf("ScanProjectForIdentifiers() text = %q, want %q", project.Comments[0].Text, want)
+			}
+		})
+	}
+}
+
+func TestScanProjectForIdentifiersReportsCopiesOnce(t *testing.T) {
+	text := "@var id Contact jane.doe@example.org"
+	project := model.Project{
+		Comments:  []model.Comment{{Filename: "a.sas", LineNum: 2, Text: text}},
+		Variables: []model.Variable{{Name: "id", Description: "Contact jane.doe@example.org", Filename: "a.sas", LineNum: 2}},
+	}
+	findings := ScanProjectForIdentifiers(&project, BuiltinDetectors, true)
+	if len(findings) != 1 || findings[0].Filename != "a.sas" || findings[0].LineNum != 2 {
+		t.Errorf("ScanProjectForIdentifiers() findings = %v, want one", findings)
+	}
+	if project.Comments[0].Text != "@var id Contact [redacted email address]" ||
+		project.Variables[0].Description != "Contact [redacted email address]" {
+		t.Errorf("ScanProjectForIdentifiers() left a copy unredacted: %q, %q", project.Comments[0].Text, project.Variables[0].Description)
+	}
+}
@@ -0,0 +1,632 @@
+/*
+ * Functions for reading the comments, docstrings, functions and imports of Python code
+ */
+
+package parse
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches a |def name(| or |class Name| statement, along with its indentation
+	pythonDefRegex = regexp.MustCompile("(?m)^([ \\t]*)(?:async[ \\t]+)?(def|class)[ \\t]+([a-zA-Z_][a-zA-Z0-9_]*)")
+
+	// matches the |import a, b as c| and |from a import b| statements
+	pythonImportRegex = regexp.MustCompile("(?m)^[ \\t]*(?:(from)[ \\t]+([a-zA-Z0-9_.]+)[ \\t]+import\\b|(import)[ \\t]+([^\\n;]+))")
+
+	// matches the calls that run another script, import a module by name or run an external command
+	pythonCallRegex = regexp.MustCompile("(?:^|[^a-zA-Z0-9_.])(exec[ \\t]*\\([ \\t]*open|(?:runpy\\.)?run_path|(?:importlib\\.)?import_module|os\\.system|os\\.popen|subprocess\\.(?:run|call|check_call|check_output|Popen))[ \\t]*\\(")
+
+	// matches the prefix of a string literal, such as the |r| of |r"\d+"|
+	pythonPrefixRegex = regexp.MustCompile("(?i)(?:^|[^a-zA-Z0-9_])([rbuf]{1,2})$")
+
+	// matches the |:param type name: description| field of a reStructuredText docstring
+	sphinxParamRegex = regexp.MustCompile("^:(?:param|parameter|arg|argument|key|keyword)[ \\t]+(?:[^:]*[ \\t])?(\\*{0,2}[a-zA-Z_][a-zA-Z0-9_]*)[ \\t]*:[ \\t]*(.*)$")
+
+	// matches the |Args:| or |Returns:| header of a section of a Google style docstring
+	googleSectionRegex = regexp.MustCompile("^([A-Z][a-zA-Z]*(?: [A-Z]?[a-zA-Z]+)*):$")
+
+	// matches the |name (type): description| entry of a section of a Google style docstring
+	googleParamRegex = regexp.MustCompile("^(\\*{0,2}[a-zA-Z_][a-zA-Z0-9_]*)[ \\t]*(?:\\([^)]*\\))?[ \\t]*:[ \\t]*(.*)$")
+
+	// matches the |name, name : type| entry of a section of a NumPy style docstring
+	numpyParamRegex = regexp.MustCompile("^(\\*{0,2}[a-zA-Z_][a-zA-Z0-9_]*(?:[ \\t]*,[ \\t]*\\*{0,2}[a-zA-Z_][a-zA-Z0-9_]*)*)[ \\t]*(?::.*)?$")
+
+	// matches the dashes underlining the header of a section of a NumPy style docstring
+	numpyUnderlineRegex = regexp.MustCompile("^-{3,}$")
+
+	// headers of the docstring sections that document parameters
+	pythonParamSections = map[string]bool{
+		"Args": true, "Arguments": true, "Parameters": true, "Params": true,
+		"Keyword Args": true, "Keyword Arguments": true, "Other Parameters": true,
+	}
+)
+
+// pythonSpans ... obtain the [start, end) offsets of each |#| comment and each string literal of a given Python string
+//
+// The span of a string literal includes its prefix, such as the |r| of a raw
+// string, and its quotes, which may be tripled for a string spanning lines.
+func pythonSpans(contents string) ([][2]int, [][2]int) {
+
+	comments := make([][2]int, 0)
+	literals := make([][2]int, 0)
+
+	for i := 0; i < len(contents); i++ {
+		c := contents[i]
+
+		switch c {
+
+		case '#':
+			end := strings.IndexByte(contents[i:], '\n')
+			if end == -1 {
+				end = len(contents)
+			} else {
+				end += i
+			}
+			comments = append(comments, [2]int{i, end})
+			i = end
+
+		// a backslash escapes the next character, even inside of raw strings
+		case '"', '\'':
+			start := i
+
+			// a prefix is at most two letters, so only those and the character before them are of interest
+			from := i - 3
+			if from < 0 {
+				from = 0
+			}
+			if prefix := pythonPrefixRegex.FindStringSubmatch(contents[from:i]); prefix != nil {
+				start -= len(prefix[1])
+			}
+			quote := contents[i : i+1]
+			if strings.HasPrefix(contents[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			j := i + len(quote)
+			for ; j < len(contents) && !strings.HasPrefix(contents[j:], quote); j++ {
+				if contents[j] == '\\' {
+					j++
+				} else if contents[j] == '\n' && len(quote) == 1 {
+					break
+				}
+			}
+			end := j + len(quote)
+			if end > len(contents) {
+				end = len(contents)
+			}
+			literals = append(literals, [2]int{start, end})
+			i = end - 1
+		}
+	}
+
+	return comments, literals
+}
+
+// StripPythonComments ... replace every Python comment in a given string with spaces
+//
+// Newlines are retained so that offsets and line numbers into the stripped
+// string match those of the original.
+func StripPythonComments(contents string) string {
+	stripped := []byte(contents)
+	comments, _ := pythonSpans(contents)
+	for _, span := range comments {
+		blankRange(stripped, span[0], span[1])
+	}
+	return string(stripped)
+}
+
+// pythonStructure ... replace the comments and string literals of a given Python string with spaces, leaving only the code
+func pythonStructure(contents string) string {
+	stripped := []byte(contents)
+	comments, literals := pythonSpans(contents)
+	for _, span := range append(comments, literals...) {
+		blankRange(stripped, span[0], span[1])
+	}
+	return string(stripped)
+}
+
+// pythonStringText ... obtain the contents of a string literal without its prefix and quotes
+func pythonStringText(literal string) string {
+	literal = strings.TrimLeft(literal, "rRbBuUfF")
+	for _, quote := range []string{"\"\"\"", "'''", "\"", "'"} {
+		if strings.HasPrefix(literal, quote) {
+			return strings.TrimSuffix(strings.TrimPrefix(literal, quote), quote)
+		}
+	}
+	return literal
+}
+
+// pythonDocstringAt ... obtain the index of the string literal that starts the code following an offset, or -1 if there is none
+//
+// The comments of the code are expected to be blanked, but not its strings.
+func pythonDocstringAt(code string, literals [][2]int, offset int) int {
+	if offset >= len(code) {
+		return -1
+	}
+	start := offset + len(code[offset:]) - len(strings.TrimLeft(code[offset:], " \t\r\n"))
+	for i, literal := range literals {
+		if literal[0] == start {
+			return i
+		}
+	}
+	return -1
+}
+
+// pythonHeaderEnd ... obtain the offset of the colon ending a |def| or |class| statement that starts at a given offset, or -1 if there is none
+func pythonHeaderEnd(code string, offset int) int {
+	depth := 0
+	for i := offset; i < len(code); i++ {
+		switch code[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ':':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// pythonBlockEnd ... obtain the offset of the end of the last line of the block of a statement ending at an offset
+//
+// The block is made up of the lines indented further than the statement, up
+// to the first line of code that is not. Blank lines do not end a block.
+func pythonBlockEnd(code string, offset int, indent int) int {
+	end := strings.IndexByte(code[offset:], '\n')
+	if end == -1 {
+		return len(code)
+	}
+	end += offset
+	for lineStart := end + 1; lineStart < len(code); {
+		lineEnd := strings.IndexByte(code[lineStart:], '\n')
+		if lineEnd == -1 {
+			lineEnd = len(code)
+		} else {
+			lineEnd += lineStart
+		}
+		line := strings.TrimRight(code[lineStart:lineEnd], " \t\r")
+		if strings.TrimSpace(line) != "" {
+			if len(line)-len(strings.TrimLeft(line, " \t")) <= indent {
+				break
+			}
+			end = lineStart + len(line)
+		}
+		lineStart = lineEnd + 1
+	}
+	return end
+}
+
+// ParseStringForPythonComments ... obtain the |#| comments and docstrings from a given Python string
+//
+// Consecutive lines of |#| comments form a single comment, as set out by
+// lineComments. The paragraphs of a docstring are comments of their own, so
+// that an |@keyword| paragraph does not take in the |Args:| section following
+// it.
+func ParseStringForPythonComments(contents string) []model.Comment {
+
+	comments := newLineComments()
+	commentSpans, literals := pythonSpans(contents)
+
+	for _, span := range commentSpans {
+
+		lineNum := LineNumberAt(contents, span[0])
+		if lineNum == 1 && strings.HasPrefix(contents, "#!") {
+			continue
+		}
+
+		lineStart := strings.LastIndexByte(contents[:span[0]], '\n') + 1
+		wholeLine := strings.TrimSpace(contents[lineStart:span[0]]) == ""
+		comments.add(lineNum, wholeLine, "#", strings.TrimSpace(strings.TrimLeft(contents[span[0]:span[1]], "#")), false)
+	}
+
+	code := pythonStructure(contents)
+	stripped := StripPythonComments(contents)
+	docstrings := make([]int, 0)
+	if i := pythonDocstringAt(stripped, literals, 0); i != -1 {
+		docstrings = append(docstrings, i)
+	}
+	for _, sindex := range pythonDefRegex.FindAllStringIndex(code, -1) {
+		headerEnd := pythonHeaderEnd(code, sindex[1])
+		if headerEnd == -1 {
+			continue
+		}
+		if i := pythonDocstringAt(stripped, literals, headerEnd+1); i != -1 {
+			docstrings = append(docstrings, i)
+		}
+	}
+
+	// the lines of each paragraph of a docstring are continued as those of |#| comments are
+	for _, i := range docstrings {
+		literal := literals[i]
+		lineNum := LineNumberAt(contents, literal[0])
+		comments.end()
+		for j, line := range strings.Split(pythonStringText(contents[literal[0]:literal[1]]), "\n") {
+			if text := strings.TrimSpace(line); text != "" {
+				comments.add(lineNum+j, true, "docstring", text, false)
+			} else {
+				comments.end()
+			}
+		}
+	}
+
+	sort.SliceStable(comments.comments, func(i, j int) bool {
+		return comments.comments[i].LineNum < comments.comments[j].LineNum
+	})
+	return comments.comments
+}
+
+// pythonScope object definition
+type pythonScope struct {
+
+	// whether the scope is that of a class rather than a function
+	class bool
+
+	// name of the scope, qualified by the names of the classes it is in
+	name string
+
+	// offset of the end of the block of the scope
+	end int
+
+	// index of the macro of a class, or -1 if it was not cataloged
+	macro int
+}
+
+// ParseStringForPythonFunctions ... obtain all of the |def| and |class| definitions from a given Python string
+//
+// Methods are named after their class, e.g. |Cohort.load|, and lack their
+// |self| or |cls| parameter. The parameters of a class are those of its
+// |__init__| method, which like the other special methods is not listed on
+// its own. Functions defined inside of another function are part of it.
+func ParseStringForPythonFunctions(filename string, contents string) []model.Macro {
+
+	functions := make([]model.Macro, 0)
+	code := pythonStructure(contents)
+	stripped := StripPythonComments(contents)
+	_, literals := pythonSpans(contents)
+	scopes := make([]pythonScope, 0)
+
+	for _, sindex := range pythonDefRegex.FindAllStringSubmatchIndex(code, -1) {
+
+		for len(scopes) > 0 && scopes[len(scopes)-1].end <= sindex[0] {
+			scopes = scopes[:len(scopes)-1]
+		}
+		if len(scopes) > 0 && !scopes[len(scopes)-1].class {
+			continue
+		}
+
+		// a header without its colon is not yet a definition, as when the code is cut short
+		name := code[sindex[6]:sindex[7]]
+		headerEnd := pythonHeaderEnd(code, sindex[7])
+		if headerEnd == -1 {
+			continue
+		}
+		scope := pythonScope{
+			class: code[sindex[4]:sindex[5]] == "class",
+			name:  name,
+			end:   pythonBlockEnd(code, headerEnd, sindex[3]-sindex[2]),
+			macro: -1,
+		}
+		var parent *pythonScope
+		if len(scopes) > 0 {
+			parent = &scopes[len(scopes)-1]
+			scope.name = parent.name + "." + name
+		}
+
+		function := model.Macro{
+			Name:       scope.name,
+			Filename:   filename,
+			LineNum:    LineNumberAt(code, sindex[6]),
+			EndLineNum: LineNumberAt(code, scope.end),
+			Language:   model.LanguagePython,
+		}
+		if i := pythonDocstringAt(stripped, literals, headerEnd+1); i != -1 {
+			function.Summary, function.DocumentedParams = parsePythonDocstring(
+				pythonStringText(contents[literals[i][0]:literals[i][1]]), LineNumberAt(contents, literals[i][0]))
+		}
+
+		// the parameters of a function follow its name, while those of a class are its bases
+		if open := sindex[7] + len(code[sindex[7]:]) - len(strings.TrimLeft(code[sindex[7]:], " \t")); !scope.class &&
+			open < len(code) && code[open] == '(' {
+			if closing := matchingParen(code[open:]); closing != -1 {
+				function.Params = parsePythonParams(contents[open+1 : open+closing])
+			}
+		}
+		if parent != nil && len(function.Params) > 0 && !pythonStaticMethod(code, sindex[0]) &&
+			(function.Params[0].Name == "self" || function.Params[0].Name == "cls") {
+			function.Params = function.Params[1:]
+		}
+		normalizePythonDocumentedParams(function.Params, function.DocumentedParams)
+
+		switch {
+		case parent != nil && name == "__init__" && parent.macro != -1:
+			class := &functions[parent.macro]
+			class.Params = function.Params
+			if len(class.DocumentedParams) < 1 {
+				class.DocumentedParams = function.DocumentedParams
+			}
+			if class.Summary == "" {
+				class.Summary = function.Summary
+			}
+		case strings.HasPrefix(name, "__") && strings.HasSuffix(name, "__"):
+		default:
+			functions = append(functions, function)
+			if scope.class {
+				scope.macro = len(functions) - 1
+			}
+		}
+
+		scopes = append(scopes, scope)
+	}
+
+	return functions
+}
+
+// pythonStaticMethod ... determine whether the definition starting at an offset is decorated by |@staticmethod|
+func pythonStaticMethod(code string, offset int) bool {
+	lines := strings.Split(code[:offset], "\n")
+	for i := len(lines) - 2; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "@") {
+			return false
+		}
+		if line == "@staticmethod" {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePythonParams ... convert the text between the parenthesis of a |def| statement into parameters
+//
+// The |/| and |*| markers that separate positional and keyword-only
+// parameters are dropped, as are the annotations of the parameters.
+func parsePythonParams(text string) []model.MacroParam {
+	params := make([]model.MacroParam, 0)
+	for _, piece := range splitTopLevel(StripPythonComments(text), ',') {
+		piece = strings.TrimSpace(piece)
+		if piece == "" || piece == "/" || piece == "*" {
+			continue
+		}
+		param := model.MacroParam{Name: piece}
+		if eq := strings.Index(piece, "="); eq != -1 {
+			param.Name = strings.TrimSpace(piece[:eq])
+			param.Default = strings.TrimSpace(piece[eq+1:])
+			param.Keyword = true
+		}
+		if colon := strings.Index(param.Name, ":"); colon != -1 {
+			param.Name = strings.TrimSpace(param.Name[:colon])
+		}
+		params = append(params, param)
+	}
+	return params
+}
+
+// normalizePythonDocumentedParams ... give the documented parameters the |*| or |**| of the parameters they document
+func normalizePythonDocumentedParams(params []model.MacroParam, documented []model.MacroParam) {
+	for i, doc := range documented {
+		for _, param := range params {
+			if doc.Name != param.Name && strings.TrimLeft(param.Name, "*") == doc.Name {
+				documented[i].Name = param.Name
+			}
+		}
+	}
+}
+
+// parsePythonDocstring ... obtain the summary and documented parameters of a docstring starting on a given line number
+//
+// The summary is the first paragraph of the docstring, or failing that the
+// text of its first |@keyword| paragraph. Parameters are documented by
+// |:param name:| fields, or by the |Args:| and |Parameters| sections of the
+// Google and NumPy styles.
+func parsePythonDocstring(text string, lineNum int) (string, []model.MacroParam) {
+
+	summary := ""
+	fallback := ""
+	params := make([]model.MacroParam, 0)
+
+	lines := strings.Split(text, "\n")
+	indents := make([]int, len(lines))
+	for i, line := range lines {
+		indents[i] = len(line) - len(strings.TrimLeft(line, " \t"))
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	// the kind of section being read, the indentation of its header and
+	// entries, and the first parameter of the entry being read
+	section := ""
+	sectionIndent := 0
+	entryIndent := -1
+	entryStart := 0
+	paragraphEnded := false
+
+	for i, line := range lines {
+
+		// a line indented further than the entry being read continues its description
+		indent := indents[i]
+		if entryStart < len(params) && line != "" && indent > entryIndent && !strings.HasPrefix(line, ":") {
+			for j := entryStart; j < len(params); j++ {
+				params[j].Description = strings.TrimSpace(params[j].Description + " " + line)
+			}
+			continue
+		}
+
+		switch {
+
+		case line == "":
+			paragraphEnded = paragraphEnded || summary != ""
+			entryStart = len(params)
+			if section == "keyword" || section == "sphinx" {
+				section = ""
+			}
+
+		case i > 0 && numpyUnderlineRegex.MatchString(line) && lines[i-1] != "":
+
+		// the header of a NumPy section is underlined on the next line
+		case i+1 < len(lines) && numpyUnderlineRegex.MatchString(lines[i+1]):
+			section = "other"
+			if pythonParamSections[line] {
+				section = "numpy"
+			}
+			sectionIndent, entryIndent, entryStart = indent, indent, len(params)
+			paragraphEnded = true
+
+		case googleSectionRegex.MatchString(line) && (i > 0 || summary != ""):
+			section = "other"
+			if pythonParamSections[strings.TrimSuffix(line, ":")] {
+				section = "google"
+			}
+			sectionIndent, entryIndent, entryStart = indent, -1, len(params)
+			paragraphEnded = true
+
+		case sphinxParamRegex.MatchString(line):
+			match := sphinxParamRegex.FindStringSubmatch(line)
+			section, entryIndent, entryStart = "sphinx", indent, len(params)
+			params = append(params, model.MacroParam{Name: match[1], Description: match[2], LineNum: lineNum + i})
+			paragraphEnded = true
+
+		case strings.HasPrefix(line, ":"):
+			section, entryStart = "other", len(params)
+			paragraphEnded = true
+
+		case leadingKeywordRegex.MatchString(line):
+			section = "keyword"
+			if fallback == "" {
+				fallback = strings.TrimSpace(line[len(leadingKeywordRegex.FindString(line)):])
+			}
+			paragraphEnded = paragraphEnded || summary != ""
+
+		case section == "google" && indent > sectionIndent:
+			if entryIndent == -1 {
+				entryIndent = indent
+			}
+			if match := googleParamRegex.FindStringSubmatch(line); match != nil && indent == entryIndent {
+				entryStart = len(params)
+				params = append(params, model.MacroParam{Name: match[1], Description: match[2], LineNum: lineNum + i})
+			}
+
+		case section == "numpy" && indent == sectionIndent:
+			if match := numpyParamRegex.FindStringSubmatch(line); match != nil {
+				entryStart = len(params)
+				for _, name := range strings.Split(match[1], ",") {
+					params = append(params, model.MacroParam{Name: strings.TrimSpace(name), LineNum: lineNum + i})
+				}
+			}
+
+		case section == "keyword" && fallback != "" && summary == "":
+			fallback = strings.TrimSpace(fallback + " " + line)
+
+		case section == "" && !paragraphEnded:
+			summary = strings.TrimSpace(summary + " " + line)
+		}
+	}
+
+	if summary == "" {
+		summary = fallback
+	}
+	return summary, params
+}
+
+// ParseStringForPythonDependencies ... obtain the imported modules, scripts run and external commands of a Python string
+//
+// Absolute imports are listed as packages, while relative imports such as
+// |from .helpers import load| are scripts of the project itself.
+func ParseStringForPythonDependencies(contents string) []model.Dependency {
+
+	dependencies := make([]model.Dependency, 0)
+	code := pythonStructure(contents)
+
+	for _, sindex := range pythonImportRegex.FindAllStringSubmatchIndex(code, -1) {
+
+		lineNum := LineNumberAt(code, sindex[0])
+		if sindex[2] != -1 {
+			dependencies = append(dependencies, pythonImport(code[sindex[4]:sindex[5]], "from", lineNum))
+			continue
+		}
+		for _, name := range strings.Split(strings.Trim(strings.TrimSpace(code[sindex[8]:sindex[9]]), "()\\"), ",") {
+			fields := strings.Fields(name)
+			if len(fields) < 1 {
+				continue
+			}
+			dependencies = append(dependencies, pythonImport(fields[0], "import", lineNum))
+		}
+	}
+
+	// the arguments of the calls are read from the code with its string literals intact
+	withStrings := StripPythonComments(contents)
+	for _, sindex := range pythonCallRegex.FindAllStringSubmatchIndex(code, -1) {
+
+		function := code[sindex[2]:sindex[3]]
+		open := sindex[1] - 1
+		closing := matchingParen(code[open:])
+		if closing == -1 {
+			continue
+		}
+		arguments := withStrings[open+1 : open+closing]
+		args := splitTopLevel(arguments, ',')
+
+		dependency := model.Dependency{LineNum: LineNumberAt(code, sindex[2]), Statement: function}
+
+		switch {
+
+		// only scripts and modules given as a literal are known
+		case strings.HasPrefix(function, "exec"):
+			dependency.Kind = model.DependencyScript
+			dependency.Statement = "exec"
+			dependency.Path = unquotePythonString(rArgument(args, "file"))
+		case strings.HasSuffix(function, "run_path"):
+			dependency.Kind = model.DependencyScript
+			dependency.Path = unquotePythonString(rArgument(args, "path_name"))
+		case strings.HasSuffix(function, "import_module"):
+			dependency.Kind = model.DependencyPackage
+			dependency.Path = unquotePythonString(rArgument(args, "name"))
+
+		// a command given as a list of literals is joined as the shell would see it
+		default:
+			dependency.Kind = model.DependencyCommand
+			command := strings.TrimSpace(rArgument(args, "args"))
+			dependency.Path = strings.Join(strings.Fields(arguments), " ")
+			if unquoted := unquotePythonString(command); unquoted != "" {
+				dependency.Path = unquoted
+			} else if list := strings.TrimSpace(arguments); strings.HasPrefix(list, "[") && strings.Contains(list, "]") {
+				words := make([]string, 0)
+				for _, word := range splitTopLevel(list[1:strings.Index(list, "]")], ',') {
+					if word = unquotePythonString(strings.TrimSpace(word)); word != "" {
+						words = append(words, word)
+					}
+				}
+				if len(words) > 0 {
+					dependency.Path = strings.Join(words, " ")
+				}
+			}
+		}
+
+		if dependency.Path == "" {
+			continue
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies
+}
+
+// pythonImport ... obtain the dependency of an imported module, which is a script of the project if the import is relative
+func pythonImport(module string, statement string, lineNum int) model.Dependency {
+	dependency := model.Dependency{LineNum: lineNum, Path: module, Statement: statement, Kind: model.DependencyPackage}
+	if strings.HasPrefix(module, ".") {
+		dependency.Kind = model.DependencyScript
+	}
+	return dependency
+}
+
+// unquotePythonString ... obtain the contents of a plain or raw Python string literal, or blank if it is not one
+func unquotePythonString(str string) string {
+	return unquoteRString(strings.TrimLeft(str, "rRuU"))
+}
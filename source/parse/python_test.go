@@ -0,0 +1,158 @@
+package parse
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestParseStringForPythonComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"keyword", "# @note Reads the data\nx = 1\n", []string{"1 @note |Reads the data"}},
+		{"continued keyword", "# @stat Fit the model\n#   with robust errors\n", []string{"1 @stat |Fit the model with robust errors"}},
+		{"trailing comment", "x = 1  # @note Trailing\n# @note Next\n", []string{"1 @note |Trailing", "2 @note |Next"}},
+		{"hash in string", "x = \"# not\" + '''\n# not either\n'''  # real\n", []string{"3 |real"}},
+		{"shebang", "#!/usr/bin/env python3\n# @note Script\n", []string{"2 @note |Script"}},
+		{"module docstring", "#!/usr/bin/env python3\n\"\"\"Prepare the cohort.\n\n@note Runs nightly\n\"\"\"\n",
+			[]string{"2 |Prepare the cohort.", "4 @note |Runs nightly"}},
+		{"function docstring", "def f(x):\n    r'''@stat Uses the t-test\n    of Welch\n\n    Args:\n        x: Values\n    '''\n",
+			[]string{"2 @stat |Uses the t-test of Welch", "5 |Args: x: Values"}},
+		{"not a docstring", "x = 1\n\"\"\"@note Not documentation\"\"\"\n", []string{}},
+		{"function header cut short", "def f(a", []string{}},
+		{"class header cut short", "class A", []string{}},
+		{"header at the end of the code", "x = 1\ndef f", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, cmt := range ParseStringForPythonComments(tt.contents) {
+				got = append(got, strconv.Itoa(cmt.LineNum)+" "+cmt.Keyword+"|"+cmt.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForPythonComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringForPythonFunctions(t *testing.T) {
+	contents := "import os\n" +
+		"\n" +
+		"def summarise(data, col: str, *args, na_rm=True, **kwargs) -> float:\n" +
+		"    \"\"\"Summarise a column\n" +
+		"\n" +
+		"    Args:\n" +
+		"        data (DataFrame): The data\n" +
+		"        col: The column,\n" +
+		"            by name\n" +
+		"        args: Extra columns\n" +
+		"\n" +
+		"    Returns:\n" +
+		"        The mean\n" +
+		"    \"\"\"\n" +
+		"    def inner(x):\n" +
+		"        return x\n" +
+		"    return data[col].mean()\n" +
+		"\n" +
+		"class Cohort(object):\n" +
+		"    \"\"\"A cohort of patients\n" +
+		"\n" +
+		"    Parameters\n" +
+		"    ----------\n" +
+		"    path, cutoff : str\n" +
+		"        Where and until when\n" +
+		"    \"\"\"\n" +
+		"    def __init__(self, path, cutoff=None):\n" +
+		"        self.path = path\n" +
+		"\n" +
+		"    @staticmethod\n" +
+		"    def load(path):\n" +
+		"        \"\"\":param path: The file\n" +
+		"            to read\n" +
+		"        \"\"\"\n" +
+		"        return path\n"
+	functions := ParseStringForPythonFunctions("a.py", contents)
+
+	got := make([]string, 0)
+	for _, f := range functions {
+		line := f.Name + " " + strconv.Itoa(f.LineNum) + "-" + strconv.Itoa(f.EndLineNum) + " " + f.Language + " " + f.Summary + " ("
+		for _, param := range f.Params {
+			line += param.Name
+			if param.Keyword {
+				line += "=" + param.Default
+			}
+			line += ","
+		}
+		line += ")"
+		for _, param := range f.DocumentedParams {
+			line += " " + param.Name + ":" + strconv.Itoa(param.LineNum) + ": " + param.Description
+		}
+		got = append(got, line)
+	}
+	want := []string{
+		"summarise 3-17 python Summarise a column (data,col,*args,na_rm=True,**kwargs,) " +
+			"data:7: The data col:8: The column, by name *args:10: Extra columns",
+		"Cohort 19-35 python A cohort of patients (path,cutoff=None,) path:24: Where and until when cutoff:24: Where and until when",
+		"Cohort.load 31-35 python  (path,) path:32: The file to read",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStringForPythonFunctions() = %q, want %q", got, want)
+	}
+}
+
+func TestParseStringForPythonFunctionsWithoutColon(t *testing.T) {
+	tests := []struct {
+		contents string
+		want     []string
+	}{
+		{"def f(a", []string{}},
+		{"class A", []string{}},
+		{"x = 1\ndef f", []string{}},
+		{"def f(a):\n    pass\ndef g", []string{"f"}},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Quote(tt.contents), func(t *testing.T) {
+			got := make([]string, 0)
+			for _, f := range ParseStringForPythonFunctions("a.py", tt.contents) {
+				got = append(got, f.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForPythonFunctions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringForPythonDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"import", "import os, numpy as np\nfrom pandas import read_csv\n",
+			[]string{"package import os", "package import numpy", "package from pandas"}},
+		{"relative import", "from .helpers import load\n", []string{"script from .helpers"}},
+		{"indented import", "try:\n    import pyreadstat\nexcept ImportError:\n    pass\n", []string{"package import pyreadstat"}},
+		{"run script", "exec(open(\"setup.py\").read())\nrunpy.run_path('clean.py')\n",
+			[]string{"script exec setup.py", "script runpy.run_path clean.py"}},
+		{"import by name", "importlib.import_module(\"statsmodels.api\")\n", []string{"package importlib.import_module statsmodels.api"}},
+		{"command", "os.system(\"rm -rf tmp\")\nsubprocess.run([\"sas\", \"-sysin\", 'a.sas'], check=True)\n",
+			[]string{"command os.system rm -rf tmp", "command subprocess.run sas -sysin a.sas"}},
+		{"commented out", "# import os\nx = \"import sys\"\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, dependency := range ParseStringForPythonDependencies(tt.contents) {
+				got = append(got, dependency.Kind+" "+dependency.Statement+" "+dependency.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForPythonDependencies() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+/*
+ * Functions for reading the comments, functions and dependencies of R code
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches the start of a |name <- function(args)| definition, also via |=| or |<<-|
+	rFunctionRegex = regexp.MustCompile("(?m)^[ \\t]*([a-zA-Z.][a-zA-Z0-9._]*|`[^`\\n]+`)[ \\t]*(?:<<?-|=)[ \\t]*function[ \\t]*\\(")
+
+	// matches the |source()|, |library()| and |require()| calls, along with the
+	// |system()|, |system2()| and |shell()| calls that run external commands
+	rCallRegex = regexp.MustCompile("(?:^|[^a-zA-Z0-9._])(source|sys\\.source|library|require|requireNamespace|system2?|shell)[ \\t]*\\(")
+
+	// matches a syntactically valid R name
+	rNameRegex = regexp.MustCompile("^[a-zA-Z.][a-zA-Z0-9._]*$")
+
+	// tags of roxygen itself, which document a function rather than the project
+	roxygenTags = map[string]bool{
+		"aliases": true, "concept": true, "describeIn": true, "description": true,
+		"details": true, "docType": true, "encoding": true, "eval": true,
+		"evalRd": true, "example": true, "examples": true, "export": true,
+		"exportClass": true, "exportMethod": true, "exportPattern": true,
+		"family": true, "field": true, "format": true, "import": true,
+		"importClassesFrom": true, "importFrom": true, "importMethodsFrom": true,
+		"include": true, "inherit": true, "inheritDotParams": true,
+		"inheritParams": true, "inheritSection": true, "keywords": true,
+		"md": true, "method": true, "name": true, "noMd": true, "noRd": true,
+		"order": true, "param": true, "rawNamespace": true, "rawRd": true,
+		"rdname": true, "references": true, "return": true, "returns": true,
+		"section": true, "seealso": true, "slot": true, "source": true,
+		"template": true, "templateVar": true, "title": true, "usage": true,
+		"useDynLib": true,
+	}
+)
+
+// rCommentSpans ... obtain the [start, end) offsets of each |#| comment of a given R string
+//
+// Strings quoted by |"|, |'| or backticks, along with |r"(...)"| raw strings,
+// are skipped so that a |#| inside of them is not taken for a comment.
+func rCommentSpans(contents string) [][2]int {
+
+	spans := make([][2]int, 0)
+
+	for i := 0; i < len(contents); i++ {
+		c := contents[i]
+
+		switch {
+
+		case c == '#':
+			end := strings.IndexByte(contents[i:], '\n')
+			if end == -1 {
+				end = len(contents)
+			} else {
+				end += i
+			}
+			spans = append(spans, [2]int{i, end})
+			i = end
+
+		case (c == 'r' || c == 'R') && (i == 0 || !isRNameChar(contents[i-1])) && rRawStringEnd(contents, i) != -1:
+			i = rRawStringEnd(contents, i) - 1
+
+		// quoted strings may span lines, and escape their quotes with a backslash
+		case c == '"' || c == '\'' || c == '`':
+			j := i + 1
+			for ; j < len(contents) && contents[j] != c; j++ {
+				if contents[j] == '\\' && c != '`' {
+					j++
+				}
+			}
+			i = j
+		}
+	}
+
+	return spans
+}
+
+// isRNameChar ... whether a given character may be part of an R name
+func isRNameChar(c byte) bool {
+	return c == '.' || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// rRawStringEnd ... obtain the offset just past the |r"-(...)-"| raw string starting at i, or -1 if there is none
+func rRawStringEnd(contents string, i int) int {
+
+	j := i + 1
+	if j >= len(contents) || (contents[j] != '"' && contents[j] != '\'') {
+		return -1
+	}
+	quote := contents[j]
+
+	// any number of dashes may be given between the quote and the bracket
+	j++
+	dashes := 0
+	for j < len(contents) && contents[j] == '-' {
+		dashes++
+		j++
+	}
+	if j >= len(contents) {
+		return -1
+	}
+	closing := map[byte]byte{'(': ')', '[': ']', '{': '}'}[contents[j]]
+	if closing == 0 {
+		return -1
+	}
+
+	terminator := string(closing) + strings.Repeat("-", dashes) + string(quote)
+	end := strings.Index(contents[j+1:], terminator)
+	if end == -1 {
+		return len(contents)
+	}
+	return j + 1 + end + len(terminator)
+}
+
+// StripRComments ... replace every R comment in a given string with spaces
+//
+// Newlines are retained so that offsets and line numbers into the stripped
+// string match those of the original.
+func StripRComments(contents string) string {
+	stripped := []byte(contents)
+	for _, span := range rCommentSpans(contents) {
+		blankRange(stripped, span[0], span[1])
+	}
+	return string(stripped)
+}
+
+// rCommentText ... obtain the text of a comment without its |#| or |#'| marker, and whether it is roxygen
+func rCommentText(comment string) (string, bool) {
+	if strings.HasPrefix(comment, "#'") {
+		return strings.TrimSpace(comment[2:]), true
+	}
+	return strings.TrimSpace(strings.TrimLeft(comment, "#")), false
+}
+
+// ParseStringForRComments ... obtain the |#| and |#'| roxygen comments from a given R string
+//
+// Consecutive lines of comments of the same kind form a single comment, as
+// set out by lineComments. Roxygen tags such as |@param| or |@export|
+// document a function rather than the project, so they are left to
+// ParseStringForRFunctions.
+func ParseStringForRComments(contents string) []model.Comment {
+
+	comments := newLineComments()
+
+	for _, span := range rCommentSpans(contents) {
+
+		lineNum := LineNumberAt(contents, span[0])
+		if lineNum == 1 && strings.HasPrefix(contents, "#!") {
+			continue
+		}
+
+		lineStart := strings.LastIndexByte(contents[:span[0]], '\n') + 1
+		wholeLine := strings.TrimSpace(contents[lineStart:span[0]]) == ""
+		text, roxygen := rCommentText(contents[span[0]:span[1]])
+
+		kind := "#"
+		if roxygen {
+			kind = "#'"
+		}
+		tag := strings.TrimSpace(strings.TrimPrefix(leadingKeywordRegex.FindString(text), "@"))
+		comments.add(lineNum, wholeLine, kind, text, roxygen && roxygenTags[tag])
+	}
+
+	return comments.comments
+}
+
+// ParseStringForRFunctions ... obtain all of the |name <- function(args)| definitions from a given R string
+func ParseStringForRFunctions(filename string, contents string) []model.Macro {
+
+	functions := make([]model.Macro, 0)
+	code := StripRComments(contents)
+	lines := strings.Split(contents, "\n")
+
+	// functions defined inside of another function are part of that function
+	searchFrom := 0
+
+	for _, sindex := range rFunctionRegex.FindAllStringSubmatchIndex(code, -1) {
+
+		if sindex[0] < searchFrom {
+			continue
+		}
+
+		function := model.Macro{
+			Name:     strings.Trim(code[sindex[2]:sindex[3]], "`"),
+			Filename: filename,
+			LineNum:  LineNumberAt(code, sindex[2]),
+			Language: model.LanguageR,
+		}
+
+		// the body is either a block in braces, or a single expression that
+		// ends the line, defaulting to the end of the file
+		open := sindex[1] - 1
+		end := len(code)
+		if closing := matchingParen(code[open:]); closing != -1 {
+			function.Params = parseMacroParams(code[open+1 : open+closing])
+			end = open + closing + 1
+			rest := strings.TrimLeft(code[end:], " \t\r\n")
+			if strings.HasPrefix(rest, "{") {
+				bodyStart := len(code) - len(rest)
+				end = len(code)
+				if brace := matchingRBrace(rest); brace != -1 {
+					end = bodyStart + brace + 1
+				}
+			} else if newline := strings.IndexByte(code[end:], '\n'); newline != -1 {
+				end += newline
+			} else {
+				end = len(code)
+			}
+		}
+		function.EndLineNum = LineNumberAt(code, end)
+
+		function.Summary, function.DocumentedParams = parseRoxygen(lines, function.LineNum)
+
+		functions = append(functions, function)
+		searchFrom = end
+	}
+
+	return functions
+}
+
+// matchingRBrace ... obtain the index of the brace closing the one that starts the string, skipping quoted strings
+func matchingRBrace(str string) int {
+	depth := 0
+	for i := 0; i < len(str); i++ {
+		switch c := str[i]; c {
+		case '"', '\'', '`':
+			for i++; i < len(str) && str[i] != c; i++ {
+				if str[i] == '\\' && c != '`' {
+					i++
+				}
+			}
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseRoxygen ... obtain the summary and documented parameters from the comment lines directly above a given line number
+//
+// The summary is the first paragraph of the comments, or failing that the
+// text of a |@title|, |@description| or |@keyword| tag. Plain |#| comments
+// are read as well as roxygen ones.
+func parseRoxygen(lines []string, lineNum int) (string, []model.MacroParam) {
+
+	first := lineNum - 1
+	for first > 0 && strings.HasPrefix(strings.TrimSpace(lines[first-1]), "#") &&
+		!strings.HasPrefix(lines[first-1], "#!") {
+		first--
+	}
+
+	summary := ""
+	fallback := ""
+	params := make([]model.MacroParam, 0)
+	tag := ""
+	paragraphEnded := false
+
+	for i := first; i < lineNum-1; i++ {
+
+		text, _ := rCommentText(strings.TrimSpace(lines[i]))
+		if keyword := leadingKeywordRegex.FindString(text); keyword != "" {
+			tag = strings.TrimSpace(strings.TrimPrefix(keyword, "@"))
+			text = strings.TrimSpace(text[len(keyword):])
+
+			// handle the |@param name Description| tags, which may name several parameters
+			if tag == "param" {
+				fields := strings.SplitN(text, " ", 2)
+				description := ""
+				if len(fields) > 1 {
+					description = strings.TrimSpace(fields[1])
+				}
+				for _, name := range strings.Split(fields[0], ",") {
+					if strings.TrimSpace(name) == "" {
+						continue
+					}
+					params = append(params, model.MacroParam{Name: strings.TrimSpace(name), Description: description, LineNum: i + 1})
+				}
+				continue
+			}
+		}
+
+		switch {
+		case tag == "param":
+			// a |@param| without a name has no description to continue
+			if text != "" && len(params) > 0 {
+				params[len(params)-1].Description = strings.TrimSpace(params[len(params)-1].Description + " " + text)
+			}
+		case tag == "" && text == "":
+			paragraphEnded = summary != ""
+		case tag == "" && !paragraphEnded:
+			summary = strings.TrimSpace(summary + " " + text)
+		case tag == "title" || tag == "description" || !roxygenTags[tag]:
+			fallback = strings.TrimSpace(fallback + " " + text)
+		}
+	}
+
+	if summary == "" {
+		summary = fallback
+	}
+	return summary, params
+}
+
+// ParseStringForRDependencies ... obtain the sourced scripts, attached packages and external commands of an R string
+func ParseStringForRDependencies(contents string) []model.Dependency {
+
+	dependencies := make([]model.Dependency, 0)
+	code := StripRComments(contents)
+
+	for _, sindex := range rCallRegex.FindAllStringSubmatchIndex(code, -1) {
+
+		function := code[sindex[2]:sindex[3]]
+		open := sindex[1] - 1
+		closing := matchingParen(code[open:])
+		if closing == -1 {
+			continue
+		}
+		arguments := code[open+1 : open+closing]
+		args := splitTopLevel(arguments, ',')
+
+		dependency := model.Dependency{LineNum: LineNumberAt(code, sindex[2]), Statement: function}
+
+		switch function {
+
+		// only scripts given as a literal path are known
+		case "source", "sys.source":
+			dependency.Kind = model.DependencyScript
+			dependency.Path = unquoteRString(rArgument(args, "file"))
+
+		// packages are given by name, unless a character string is asked for
+		case "library", "require", "requireNamespace":
+			dependency.Kind = model.DependencyPackage
+			name := rArgument(args, "package")
+			dependency.Path = unquoteRString(name)
+			characterOnly := strings.HasPrefix(rArgument(args, "character.only"), "T")
+			if dependency.Path == "" && rNameRegex.MatchString(name) && function != "requireNamespace" && !characterOnly {
+				dependency.Path = name
+			}
+
+		default:
+			dependency.Kind = model.DependencyCommand
+			dependency.Path = strings.Join(strings.Fields(arguments), " ")
+			if command := unquoteRString(strings.TrimSpace(arguments)); len(args) == 1 && command != "" {
+				dependency.Path = command
+			}
+		}
+
+		if dependency.Path == "" {
+			continue
+		}
+		dependencies = append(dependencies, dependency)
+	}
+
+	return dependencies
+}
+
+// rArgument ... obtain the value of the argument of a call with the given name, or else its first unnamed argument
+func rArgument(args []string, name string) string {
+	positional := ""
+	found := false
+	for _, arg := range args {
+		arg = strings.TrimSpace(arg)
+		if eq := strings.Index(arg, "="); eq > 0 && !strings.HasPrefix(arg[eq:], "==") &&
+			rNameRegex.MatchString(strings.TrimSpace(arg[:eq])) {
+			if strings.TrimSpace(arg[:eq]) == name {
+				return strings.TrimSpace(arg[eq+1:])
+			}
+			continue
+		}
+		if !found {
+			positional, found = arg, true
+		}
+	}
+	return positional
+}
+
+// unquoteRString ... obtain the contents of a single or double quoted R string, or blank if it is not one
+func unquoteRString(str string) string {
+	if len(str) < 2 || (str[0] != '"' && str[0] != '\'') || str[len(str)-1] != str[0] {
+		return ""
+	}
+	return str[1 : len(str)-1]
+}
@@ -0,0 +1,112 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestParseStringForRComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"keyword", "# @note Reads the data\nx <- 1\n", []string{"@note |Reads the data"}},
+		{"continued keyword", "# @stat Fit the model\n#   with robust errors\n", []string{"@stat |Fit the model with robust errors"}},
+		{"keyword splits block", "# Intro\n# @note First\n# @note Second\n", []string{"|Intro", "@note |First", "@note |Second"}},
+		{"trailing comment", "x <- 1 # @note Trailing\n# @note Next\n", []string{"@note |Trailing", "@note |Next"}},
+		{"hash in string", "x <- \"# not a comment\" # real\n", []string{"|real"}},
+		{"hash in raw string", "x <- r\"(# not)\" # real\n", []string{"|real"}},
+		{"shebang", "#!/usr/bin/env Rscript\n# @note Script\n", []string{"@note |Script"}},
+		{"roxygen tags skipped", "#' Adds one\n#' @param x A number\n#'   to add to\n#' @note Exported\nadd <- function(x) x + 1\n",
+			[]string{"|Adds one", "@note |Exported"}},
+		{"empty comments", "#\n#'\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, cmt := range ParseStringForRComments(tt.contents) {
+				got = append(got, cmt.Keyword+"|"+cmt.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForRComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringForRFunctions(t *testing.T) {
+	contents := "#' Summarise a column\n" +
+		"#'\n" +
+		"#' Longer details.\n" +
+		"#' @param data,col The data and column\n" +
+		"#' @param na.rm Whether to drop\n" +
+		"#'   missing values\n" +
+		"summarise_col <- function(data, col, na.rm = TRUE) {\n" +
+		"  inner = function(x) { \"}\" }\n" +
+		"  mean(data[[col]], na.rm = na.rm)\n" +
+		"}\n" +
+		"# @note Squares\n" +
+		"`sq` = function(x) x^2\n"
+	functions := ParseStringForRFunctions("a.R", contents)
+	if len(functions) != 2 {
+		t.Fatalf("ParseStringForRFunctions() = %d functions, want 2", len(functions))
+	}
+
+	f := functions[0]
+	if f.Name != "summarise_col" || f.LineNum != 7 || f.EndLineNum != 10 || f.Language != model.LanguageR ||
+		f.Summary != "Summarise a column" || len(f.Params) != 3 || !f.Params[2].Keyword || f.Params[2].Default != "TRUE" {
+		t.Errorf("ParseStringForRFunctions() = %+v", f)
+	}
+	documented := make([]string, 0)
+	for _, param := range f.DocumentedParams {
+		documented = append(documented, param.Name+": "+param.Description)
+	}
+	want := []string{"data: The data and column", "col: The data and column", "na.rm: Whether to drop missing values"}
+	if !reflect.DeepEqual(documented, want) {
+		t.Errorf("ParseStringForRFunctions() documented = %q, want %q", documented, want)
+	}
+
+	f = functions[1]
+	if f.Name != "sq" || f.LineNum != 12 || f.EndLineNum != 12 || f.Summary != "Squares" {
+		t.Errorf("ParseStringForRFunctions() = %+v", f)
+	}
+}
+
+func TestParseStringForRFunctionsUnnamedParam(t *testing.T) {
+	functions := ParseStringForRFunctions("a.R", "#' Adds\n#' @param\n#'   the value\nf <- function(x) x + 1\n")
+	if len(functions) != 1 || functions[0].Summary != "Adds" || len(functions[0].DocumentedParams) != 0 {
+		t.Errorf("ParseStringForRFunctions() = %+v", functions)
+	}
+}
+
+func TestParseStringForRDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"source", "source(\"utils.R\")\n", []string{"script source utils.R"}},
+		{"named file", "source(local = TRUE, file = 'utils.R')\n", []string{"script source utils.R"}},
+		{"computed path", "source(file.path(root, \"utils.R\"))\n", []string{}},
+		{"library", "library(dplyr); require(\"haven\")\n", []string{"package library dplyr", "package require haven"}},
+		{"namespaced", "base::library(survival)\n", []string{"package library survival"}},
+		{"character only", "library(pkg, character.only = TRUE)\n", []string{}},
+		{"command", "system(\"rm -rf tmp\")\nsystem2(\"cp\", c(\"a\", \"b\"))\n",
+			[]string{"command system rm -rf tmp", "command system2 \"cp\", c(\"a\", \"b\")"}},
+		{"commented out", "# library(dplyr)\nmy.source(\"x.R\")\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, dependency := range ParseStringForRDependencies(tt.contents) {
+				got = append(got, dependency.Kind+" "+dependency.Statement+" "+dependency.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForRDependencies() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
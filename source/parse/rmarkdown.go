@@ -0,0 +1,118 @@
+/*
+ * Functions for splitting R Markdown and Quarto documents into their chunks of code
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches the opening fence of a chunk of code, e.g. |```{r setup, include=FALSE}|
+	chunkFenceRegex = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \\t]*\\{[ \\t]*([a-zA-Z0-9_.-]+)[ \\t]*(.*?)[ \\t]*\\}[ \\t]*$")
+
+	// matches the |#| label: name| chunk option given inside of a chunk, in any comment notation
+	chunkOptionRegex = regexp.MustCompile("^[ \\t]*(?:#|//|--|\\*|%)\\|[ \\t]*(.*)$")
+)
+
+// Chunk object definition
+type Chunk struct {
+
+	// engine of the chunk, e.g. |r|, |sas| or |stata|
+	Engine string
+
+	// label of the chunk, or |unnamed-chunk-N| as given by knitr when it has none
+	Label string
+
+	// line number that the code of the chunk starts on, just after its opening fence
+	LineNum int
+
+	// code of the chunk, with any |#|| chunk options blanked out
+	Code string
+}
+
+// ParseStringForChunks ... obtain the chunks of code of a given R Markdown or Quarto string
+//
+// The label of a chunk is the first of its options when that is not of the
+// form |name=value|, else that given by a |label=| option or a Quarto style
+// |#| label:| line, which is blanked out along with any other chunk options.
+func ParseStringForChunks(contents string) []Chunk {
+
+	chunks := make([]Chunk, 0)
+	lines := strings.Split(contents, "\n")
+	unnamed := 0
+
+	for i := 0; i < len(lines); i++ {
+
+		match := chunkFenceRegex.FindStringSubmatch(strings.TrimRight(lines[i], "\r"))
+		if match == nil {
+			continue
+		}
+		fence := match[1]
+		chunk := Chunk{Engine: strings.ToLower(match[2]), LineNum: i + 2}
+
+		// the options follow the engine, separated by a comma or a space
+		options := splitTopLevel(strings.TrimLeft(match[3], ", \t"), ',')
+		for j, option := range options {
+			option = strings.TrimSpace(option)
+			eq := strings.Index(option, "=")
+			switch {
+			case j == 0 && eq == -1:
+				chunk.Label = option
+			case eq != -1 && strings.TrimSpace(option[:eq]) == "label":
+				chunk.Label = strings.Trim(strings.TrimSpace(option[eq+1:]), "\"'")
+			case eq != -1 && strings.TrimSpace(option[:eq]) == "engine":
+				chunk.Engine = strings.ToLower(strings.Trim(strings.TrimSpace(option[eq+1:]), "\"'"))
+			}
+		}
+
+		// the chunk ends at a fence of the same kind, at least as long, or the end of the document
+		code := make([]string, 0)
+		inOptions := true
+		for i++; i < len(lines); i++ {
+			line := strings.TrimRight(lines[i], "\r")
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				break
+			}
+			if option := chunkOptionRegex.FindStringSubmatch(line); inOptions && option != nil {
+				if pieces := strings.SplitN(option[1], ":", 2); len(pieces) == 2 && strings.TrimSpace(pieces[0]) == "label" {
+					chunk.Label = strings.Trim(strings.TrimSpace(pieces[1]), "\"'")
+				}
+				line = ""
+			} else {
+				inOptions = false
+			}
+			code = append(code, line)
+		}
+		chunk.Code = strings.Join(code, "\n")
+
+		if chunk.Label == "" {
+			unnamed++
+			chunk.Label = "unnamed-chunk-" + strconv.Itoa(unnamed)
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// chunkFrontend ... obtain the front-end of the engine of a chunk, or nil if there is none
+func chunkFrontend(chunk Chunk) LanguageFrontend {
+	frontend := Frontend(chunk.Engine)
+	if frontend == nil || frontend.Name() == model.LanguageRMarkdown {
+		return nil
+	}
+	return frontend
+}
+
+// chunkContents ... obtain the code of a chunk preceded by blank lines, so that its line numbers are those of the document
+func chunkContents(chunk Chunk) string {
+	return strings.Repeat("\n", chunk.LineNum-1) + chunk.Code
+}
@@ -0,0 +1,62 @@
+package parse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestParseStringForChunks(t *testing.T) {
+	contents := "---\ntitle: A\n---\n\n" +
+		"```{r setup, include=FALSE}\nlibrary(knitr)\n```\n\n" +
+		"Some `r 1 + 1` prose\n\n" +
+		"````{sas}\n```\n````\n" +
+		"```{R}\n#| label: \"quarto\"\n#| echo: false\nx <- 1\n```\n" +
+		"```{r, echo=FALSE}\ny <- 2\n```\n" +
+		"```{r label=\"named\", engine=\"stata\"}\ndisplay 1\n```\n" +
+		"```r\nnot <- 'a chunk'\n```\n" +
+		"~~~{python}\nz = 3\n"
+	want := []Chunk{
+		{Engine: "r", Label: "setup", LineNum: 6, Code: "library(knitr)"},
+		{Engine: "sas", Label: "unnamed-chunk-1", LineNum: 12, Code: "```"},
+		{Engine: "r", Label: "quarto", LineNum: 15, Code: "\n\nx <- 1"},
+		{Engine: "r", Label: "unnamed-chunk-2", LineNum: 20, Code: "y <- 2"},
+		{Engine: "stata", Label: "named", LineNum: 23, Code: "display 1"},
+		{Engine: "python", Label: "unnamed-chunk-3", LineNum: 29, Code: "z = 3\n"},
+	}
+	got := ParseStringForChunks(contents)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStringForChunks() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseRMarkdown(t *testing.T) {
+	contents := "# Title\n\n" +
+		"```{r setup}\n# @note In R\nf <- function(x) x\n```\n\n" +
+		"```{sas}\n**@note In SAS;\n%macro m;\n%mend;\n%include 'setup.sas';\n```\n\n" +
+		"```{bash}\n# @note Not read\n```\n"
+	project, err := (&Parser{}).Parse(strings.NewReader(contents), "report.Rmd")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	got := make([]string, 0)
+	for _, cmt := range project.Comments {
+		got = append(got, cmt.Part+":"+cmt.Text)
+	}
+	want := []string{"setup:In R", "unnamed-chunk-1:In SAS"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() comments = %q, want %q", got, want)
+	}
+	if project.Languages["report.Rmd"] != model.LanguageRMarkdown || len(project.Macros) != 2 ||
+		project.Macros[0].LineNum != 5 || project.Macros[1].LineNum != 10 {
+		t.Errorf("Parse() = %v, %+v", project.Languages, project.Macros)
+	}
+	if project.Macros[0].Filename != "report.Rmd › setup" || project.Macros[1].Filename != "report.Rmd › unnamed-chunk-1" {
+		t.Errorf("Parse() macros located in %q and %q", project.Macros[0].Filename, project.Macros[1].Filename)
+	}
+	if len(project.Includes) != 1 || project.Includes[0].Filename != "report.Rmd › unnamed-chunk-1" || project.Includes[0].LineNum != 12 {
+		t.Errorf("Parse() includes = %+v", project.Includes)
+	}
+}
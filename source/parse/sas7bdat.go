@@ -0,0 +1,298 @@
+/*
+ * Functions for reading the column metadata of SAS .sas7bdat files
+ */
+
+package parse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// magic number that every .sas7bdat file starts with
+var sas7bdatMagic = []byte{
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xc2, 0xea, 0x81, 0x60, 0xb3, 0x14, 0x11, 0xcf, 0xbd, 0x92, 0x08, 0x00,
+	0x09, 0xc7, 0x31, 0x8c, 0x18, 0x1f, 0x10, 0x11,
+}
+
+// Page types, after masking, whose subheaders describe the dataset
+const (
+	sasPageMeta = 0x0000
+	sasPageMix  = 0x0200
+	sasPageAMD  = 0x0400
+	sasPageMask = 0x0f00
+	sasPageComp = 0x9000
+)
+
+// Signatures of the subheaders that describe the dataset
+const (
+	sasSubheaderRowSize      = 0xf7f7f7f7
+	sasSubheaderColumnText   = 0xfffffffd
+	sasSubheaderColumnName   = 0xffffffff
+	sasSubheaderColumnAttrs  = 0xfffffffc
+	sasSubheaderFormatLabels = 0xfffffbfe
+)
+
+// sasEncodingUTF8 ... the character encoding code of utf-8, other encodings are read as latin-1
+const sasEncodingUTF8 = 20
+
+// sas7bdatReader ... state gathered while reading the subheaders of a .sas7bdat file
+type sas7bdatReader struct {
+
+	// byte order of the numbers in the file
+	order binary.ByteOrder
+
+	// whether the file was written by a 64-bit version of SAS
+	u64 bool
+
+	// whether strings are latin-1 rather than utf-8
+	latin1 bool
+
+	// text blocks of the column text subheaders, which the other subheaders refer to
+	texts [][]byte
+
+	// column names, attributes, formats and labels, in the order read
+	names   []string
+	types   []string
+	lengths []int
+	formats []string
+	labels  []string
+}
+
+// intSize ... the size of the integers of the file, which depends on whether it is 64-bit
+func (s *sas7bdatReader) intSize() int {
+	if s.u64 {
+		return 8
+	}
+	return 4
+}
+
+// integer ... read an integer of the size used by the file at a given offset
+func (s *sas7bdatReader) integer(b []byte, offset int) int64 {
+	if s.u64 {
+		if offset+8 > len(b) {
+			return 0
+		}
+		return int64(s.order.Uint64(b[offset:]))
+	}
+	if offset+4 > len(b) {
+		return 0
+	}
+	return int64(int32(s.order.Uint32(b[offset:])))
+}
+
+// uint16At ... read a two byte integer at a given offset, or zero if out of bounds
+func (s *sas7bdatReader) uint16At(b []byte, offset int) int {
+	if offset < 0 || offset+2 > len(b) {
+		return 0
+	}
+	return int(s.order.Uint16(b[offset:]))
+}
+
+// text ... obtain the string referred to by the |index, offset, length| reference at a given offset
+func (s *sas7bdatReader) text(b []byte, offset int) string {
+	index := s.uint16At(b, offset)
+	start := s.uint16At(b, offset+2)
+	length := s.uint16At(b, offset+4)
+	if index >= len(s.texts) || length == 0 || start+length > len(s.texts[index]) {
+		return ""
+	}
+	return strings.TrimRight(dtaString(s.texts[index][start:start+length], s.latin1), " ")
+}
+
+// ReadSas7bdatFile ... obtain the column metadata of the SAS dataset stored at the given path
+func ReadSas7bdatFile(path string) (model.Dataset, error) {
+
+	file, err := os.Open(path)
+	if err != nil {
+		return model.Dataset{}, err
+	}
+	defer file.Close()
+
+	dataset, err := ReadSas7bdat(file)
+	if err != nil {
+		return dataset, model.NewError(path, 0, err)
+	}
+	dataset.Filename = path
+
+	return dataset, nil
+}
+
+// ReadSas7bdat ... obtain the column metadata of a SAS dataset; only the pages holding metadata are read in full
+func ReadSas7bdat(r io.ReadSeeker) (model.Dataset, error) {
+
+	dataset := model.Dataset{Format: "SAS"}
+	s := &sas7bdatReader{order: binary.LittleEndian}
+
+	// no page may be larger than the file itself
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return dataset, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return dataset, err
+	}
+
+	// the fields of the header that determine how the rest is read
+	header := make([]byte, 288)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return dataset, err
+	}
+	if !bytes.Equal(header[:32], sas7bdatMagic) {
+		return dataset, fmt.Errorf("not a .sas7bdat file")
+	}
+	s.u64 = header[32] == '3'
+	align := 0
+	if header[35] == '3' {
+		align = 4
+	}
+	if header[37] != 0x01 {
+		s.order = binary.BigEndian
+	}
+	s.latin1 = header[70] != sasEncodingUTF8
+
+	created := math.Float64frombits(s.order.Uint64(header[164+align:]))
+	if !math.IsNaN(created) && created > 0 {
+		dataset.Created = time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(created) * time.Second)
+	}
+	headerLength := int64(s.order.Uint32(header[196+align:]))
+	pageSize := int(s.order.Uint32(header[200+align:]))
+	pageCount := int64(s.order.Uint32(header[204+align:]))
+	if s.u64 {
+		pageCount = int64(s.order.Uint64(header[204+align:]))
+	}
+	release := strings.TrimRight(string(header[216+align+s.intSize()-4:][:8]), "\x00 ")
+	if release != "" {
+		dataset.Format += " " + release
+	}
+
+	// the pages follow the header, and only those of metadata are read in full
+	pageOffset := 16
+	pointerSize := 12
+	if s.u64 {
+		pageOffset, pointerSize = 32, 24
+	}
+	if pageSize < pageOffset+8 || int64(pageSize) > size {
+		return dataset, fmt.Errorf("invalid page size %d", pageSize)
+	}
+	page := make([]byte, pageSize)
+
+	for i := int64(0); i < pageCount; i++ {
+
+		if _, err := r.Seek(headerLength+i*int64(pageSize), io.SeekStart); err != nil {
+			return dataset, err
+		}
+		if _, err := io.ReadFull(r, page[:pageOffset+8]); err != nil {
+			return dataset, err
+		}
+		pageType := int(s.order.Uint16(page[pageOffset:]))
+		if pageType == sasPageComp || (pageType&sasPageMask != sasPageMeta &&
+			pageType&sasPageMask != sasPageMix && pageType&sasPageMask != sasPageAMD) {
+			continue
+		}
+		if _, err := io.ReadFull(r, page[pageOffset+8:]); err != nil {
+			return dataset, err
+		}
+
+		count := int(s.order.Uint16(page[pageOffset+4:]))
+		for j := 0; j < count; j++ {
+			pointer := pageOffset + 8 + j*pointerSize
+			if pointer+pointerSize > len(page) {
+				break
+			}
+			offset := int(s.integer(page, pointer))
+			length := int(s.integer(page, pointer+s.intSize()))
+			compression := page[pointer+2*s.intSize()]
+			if length <= 0 || compression != 0 || offset < 0 || length > len(page)-offset {
+				continue
+			}
+			s.readSubheader(page[offset:offset+length], &dataset)
+		}
+	}
+
+	// the subheaders give the columns in the same order
+	dataset.Variables = make([]model.DatasetVariable, len(s.names))
+	for i, name := range s.names {
+		dataset.Variables[i].Name = name
+		if i < len(s.types) {
+			dataset.Variables[i].Type = s.types[i]
+			dataset.Variables[i].Length = s.lengths[i]
+		}
+		if i < len(s.formats) {
+			dataset.Variables[i].Format = s.formats[i]
+			dataset.Variables[i].Label = s.labels[i]
+		}
+	}
+
+	return dataset, nil
+}
+
+// readSubheader ... gather the metadata of a single subheader of a page
+func (s *sas7bdatReader) readSubheader(b []byte, dataset *model.Dataset) {
+
+	size := s.intSize()
+	if len(b) < size {
+		return
+	}
+
+	// big-endian 64-bit signatures are padded at the start
+	signature := s.order.Uint32(b)
+	if s.u64 && s.order == binary.BigEndian && (signature == 0 || signature == 0xffffffff) && len(b) >= 8 {
+		signature = s.order.Uint32(b[4:])
+	}
+
+	switch signature {
+
+	case sasSubheaderRowSize:
+		dataset.Observations = s.integer(b, 6*size)
+
+	case sasSubheaderColumnText:
+		length := s.uint16At(b, size)
+		if size+length > len(b) {
+			length = len(b) - size
+		}
+		// the page is reused, so the text must be copied
+		s.texts = append(s.texts, append([]byte{}, b[size:size+length]...))
+
+	case sasSubheaderColumnName:
+		for offset := size + 8; offset+8 <= len(b)-12; offset += 8 {
+			s.names = append(s.names, s.text(b, offset))
+		}
+
+	case sasSubheaderColumnAttrs:
+		for offset := size + 8; offset+size+8 <= len(b)-12; offset += size + 8 {
+			kind := "character"
+			if b[offset+size+6] == 1 {
+				kind = "numeric"
+			}
+			s.types = append(s.types, kind)
+			s.lengths = append(s.lengths, int(s.order.Uint32(b[offset+size:])))
+		}
+
+	case sasSubheaderFormatLabels:
+		format := s.text(b, 22+3*size)
+		width := s.uint16At(b, 8+3*size)
+		digits := s.uint16At(b, 10+3*size)
+		if width > 0 {
+			format += strconv.Itoa(width)
+		}
+		if width > 0 || digits > 0 {
+			format += "."
+		}
+		if digits > 0 {
+			format += strconv.Itoa(digits)
+		}
+		s.formats = append(s.formats, format)
+		s.labels = append(s.labels, s.text(b, 28+3*size))
+	}
+}
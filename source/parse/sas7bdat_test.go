@@ -0,0 +1,219 @@
+package parse
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// sas7bdatBuilder assembles the subheaders of a .sas7bdat file for testing
+type sas7bdatBuilder struct {
+	order binary.ByteOrder
+	u64   bool
+}
+
+// integer writes an integer of the size used by the file
+func (b sas7bdatBuilder) integer(buf *bytes.Buffer, value int) {
+	if b.u64 {
+		binary.Write(buf, b.order, uint64(value))
+	} else {
+		binary.Write(buf, b.order, uint32(value))
+	}
+}
+
+// signature writes a subheader signature, padded as SAS does for 64-bit files
+func (b sas7bdatBuilder) signature(buf *bytes.Buffer, signature uint32) {
+	pad := []byte{0, 0, 0, 0}
+	if signature>>16 == 0xffff {
+		pad = []byte{0xff, 0xff, 0xff, 0xff}
+	}
+	if b.u64 && b.order == binary.BigEndian {
+		buf.Write(pad)
+	}
+	binary.Write(buf, b.order, signature)
+	if b.u64 && b.order == binary.LittleEndian {
+		buf.Write(pad)
+	}
+}
+
+// textRef writes a reference to the text at the given offset of the first column text block
+func (b sas7bdatBuilder) textRef(buf *bytes.Buffer, offset int, length int) {
+	binary.Write(buf, b.order, []uint16{0, uint16(offset), uint16(length)})
+}
+
+// subheaders assembles the subheaders of a dataset with the id and sex columns
+func (b sas7bdatBuilder) subheaders() [][]byte {
+	size := 4
+	if b.u64 {
+		size = 8
+	}
+	subheaders := make([][]byte, 0)
+
+	var rowSize bytes.Buffer
+	b.signature(&rowSize, sasSubheaderRowSize)
+	fixed(&rowSize, "", 4*size)
+	b.integer(&rowSize, 9)
+	b.integer(&rowSize, 2)
+	fixed(&rowSize, "", 32*size)
+	subheaders = append(subheaders, rowSize.Bytes())
+
+	// the text block starts with its own length
+	text := "\x00\x00\x00\x00\x00\x00\x00\x00idsexBEST$CHARPatient identifierSex"
+	var columnText bytes.Buffer
+	b.signature(&columnText, sasSubheaderColumnText)
+	binary.Write(&columnText, b.order, uint16(len(text)))
+	columnText.WriteString(text[2:])
+	subheaders = append(subheaders, columnText.Bytes())
+
+	var columnName bytes.Buffer
+	b.signature(&columnName, sasSubheaderColumnName)
+	fixed(&columnName, "", 8)
+	b.textRef(&columnName, 8, 2)
+	fixed(&columnName, "", 2)
+	b.textRef(&columnName, 10, 3)
+	fixed(&columnName, "", 2+12)
+	subheaders = append(subheaders, columnName.Bytes())
+
+	var columnAttrs bytes.Buffer
+	b.signature(&columnAttrs, sasSubheaderColumnAttrs)
+	fixed(&columnAttrs, "", 8)
+	b.integer(&columnAttrs, 0)
+	binary.Write(&columnAttrs, b.order, uint32(8))
+	columnAttrs.Write([]byte{0, 0, 1, 0})
+	b.integer(&columnAttrs, 8)
+	binary.Write(&columnAttrs, b.order, uint32(1))
+	columnAttrs.Write([]byte{0, 0, 2, 0})
+	fixed(&columnAttrs, "", 12)
+	subheaders = append(subheaders, columnAttrs.Bytes())
+
+	for _, column := range [][]int{{12, 13, 4, 22, 18}, {1, 17, 5, 40, 3}} {
+		var formatLabel bytes.Buffer
+		b.signature(&formatLabel, sasSubheaderFormatLabels)
+		fixed(&formatLabel, "", 8+3*size-size)
+		binary.Write(&formatLabel, b.order, []uint16{uint16(column[0]), 0})
+		fixed(&formatLabel, "", 10)
+		b.textRef(&formatLabel, column[1], column[2])
+		b.textRef(&formatLabel, column[3], column[4])
+		fixed(&formatLabel, "", 16)
+		subheaders = append(subheaders, formatLabel.Bytes())
+	}
+
+	return subheaders
+}
+
+// file assembles a .sas7bdat file holding a page of metadata followed by a page of data
+func (b sas7bdatBuilder) file(created time.Time) []byte {
+	const headerLength, pageSize = 1024, 4096
+	align, pageOffset, pointerSize := 0, 16, 12
+	if b.u64 {
+		align, pageOffset, pointerSize = 4, 32, 24
+	}
+
+	header := make([]byte, headerLength)
+	copy(header, sas7bdatMagic)
+	header[32], header[35] = '2', '2'
+	if b.u64 {
+		header[32], header[35] = '3', '3'
+	}
+	if b.order == binary.LittleEndian {
+		header[37] = 0x01
+	}
+	header[70] = sasEncodingUTF8
+	copy(header[84:], "SAS FILE")
+	seconds := created.Sub(time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC)).Seconds()
+	b.order.PutUint64(header[164+align:], math.Float64bits(seconds))
+	b.order.PutUint32(header[196+align:], headerLength)
+	b.order.PutUint32(header[200+align:], pageSize)
+	if b.u64 {
+		b.order.PutUint64(header[204+align:], 2)
+		copy(header[216+align+4:], "9.0401M6")
+	} else {
+		b.order.PutUint32(header[204+align:], 2)
+		copy(header[216+align:], "9.0401M6")
+	}
+
+	subheaders := b.subheaders()
+	meta := make([]byte, pageSize)
+	b.order.PutUint16(meta[pageOffset:], sasPageMeta)
+	b.order.PutUint16(meta[pageOffset+4:], uint16(len(subheaders)))
+	offset := pageOffset + 8 + len(subheaders)*pointerSize
+	for i, subheader := range subheaders {
+		var pointer bytes.Buffer
+		b.integer(&pointer, offset)
+		b.integer(&pointer, len(subheader))
+		copy(meta[pageOffset+8+i*pointerSize:], pointer.Bytes())
+		copy(meta[offset:], subheader)
+		offset += len(subheader)
+	}
+
+	// a data page whose contents would not parse as subheaders
+	data := bytes.Repeat([]byte{0xff}, pageSize)
+	b.order.PutUint16(data[pageOffset:], 0x0100)
+
+	return append(append(header, meta...), data...)
+}
+
+// withPageSize overwrites the page size given by the header of a 64-bit little-endian file
+func withPageSize(data []byte, pageSize uint32) []byte {
+	binary.LittleEndian.PutUint32(data[200+4:], pageSize)
+	return data
+}
+
+func TestReadSas7bdat(t *testing.T) {
+	created := time.Date(2020, 12, 31, 12, 30, 0, 0, time.UTC)
+	wantVariables := []model.DatasetVariable{
+		{Name: "id", Type: "numeric", Length: 8, Format: "BEST12.", Label: "Patient identifier"},
+		{Name: "sex", Type: "character", Length: 1, Format: "$CHAR1.", Label: "Sex"},
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"32-bit little-endian", sas7bdatBuilder{binary.LittleEndian, false}.file(created), false},
+		{"64-bit little-endian", sas7bdatBuilder{binary.LittleEndian, true}.file(created), false},
+		{"64-bit big-endian", sas7bdatBuilder{binary.BigEndian, true}.file(created), false},
+		{"not a sas7bdat file", make([]byte, 1024), true},
+		{"truncated file", sas7bdatBuilder{binary.LittleEndian, true}.file(created)[:2048], true},
+		{"page size beyond the file", withPageSize(sas7bdatBuilder{binary.LittleEndian, true}.file(created), 1<<32-1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataset, err := ReadSas7bdat(bytes.NewReader(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadSas7bdat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if dataset.Format != "SAS 9.0401M6" || dataset.Observations != 2 || !dataset.Created.Equal(created) {
+				t.Errorf("ReadSas7bdat() = %s with %d observations created %s", dataset.Format, dataset.Observations, dataset.Created)
+			}
+			if !reflect.DeepEqual(dataset.Variables, wantVariables) {
+				t.Errorf("ReadSas7bdat() variables = %v, want %v", dataset.Variables, wantVariables)
+			}
+		})
+	}
+}
+
+func TestReadSas7bdatSkipsSubheadersBeyondThePage(t *testing.T) {
+	b := sas7bdatBuilder{binary.LittleEndian, true}
+	data := b.file(time.Now())
+
+	// the length of the row size subheader, the first of the metadata page,
+	// is made so large that adding its offset to it overflows
+	binary.LittleEndian.PutUint64(data[1024+32+8+8:], math.MaxInt64)
+
+	dataset, err := ReadSas7bdat(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("ReadSas7bdat() error = %v", err)
+	}
+	if dataset.Observations != 0 || len(dataset.Variables) != 2 {
+		t.Errorf("ReadSas7bdat() = %d observations of %v, want the columns alone", dataset.Observations, dataset.Variables)
+	}
+}
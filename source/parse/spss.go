@@ -0,0 +1,329 @@
+/*
+ * Functions for reading the comments, dependencies and datasets of SPSS syntax
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches the |COMMENT| command, which like |*| comments out a whole command
+	spssCommentCommandRegex = regexp.MustCompile("(?i)^comment(\\s|\\.?$)")
+
+	// matches the |INCLUDE 'file'|, |INCLUDE FILE='file'| and |INSERT FILE='file'| commands
+	spssIncludeRegex = regexp.MustCompile("(?is)^(include|insert)\\s+(?:file\\s*=?\\s*)?(\"[^\"]*\"|'[^']*')")
+
+	// matches the |HOST COMMAND=['command']| command
+	spssHostRegex = regexp.MustCompile("(?is)^host\\s+command\\s*=\\s*\\[(.*)\\]")
+
+	// matches the |FILE HANDLE name /NAME='path'| command
+	spssFileHandleRegex = regexp.MustCompile("(?is)^file\\s+handle\\s+([a-zA-Z_#@$][a-zA-Z0-9_.#@$]*)\\s*/\\s*name\\s*=\\s*(\"[^\"]*\"|'[^']*')")
+
+	// matches the |FILE=|, |OUTFILE=|, |DATA=| and |TABLE=| subcommands that name a dataset or file
+	spssFileRegex = regexp.MustCompile("(?i)(?:^|[\\s/])(file|outfile|data|table)\\s*=\\s*(\"[^\"]*\"|'[^']*'|\\*|[^\\s/]+)")
+)
+
+// spssCommentSpans ... obtain the [start, end) offsets of each comment of a given SPSS string
+//
+// A command starting with |*| or |COMMENT| is a comment up to the period that
+// ends a line, or up to a blank line, whichever comes first. A |/*| comment
+// runs up to the |*/| or the end of the line.
+func spssCommentSpans(contents string) [][2]int {
+
+	spans := make([][2]int, 0)
+	atCommandStart := true
+	inComment := false
+	offset := 0
+
+	for _, line := range strings.SplitAfter(contents, "\n") {
+
+		start := offset
+		offset += len(line)
+		end := start + len(strings.TrimRight(line, "\r\n"))
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+
+		// a blank line ends any command
+		case trimmed == "":
+			inComment = false
+			atCommandStart = true
+
+		case inComment:
+			spans[len(spans)-1][1] = end
+			inComment = !strings.HasSuffix(trimmed, ".")
+			atCommandStart = !inComment
+
+		case atCommandStart && (strings.HasPrefix(trimmed, "*") || spssCommentCommandRegex.MatchString(trimmed)):
+			spans = append(spans, [2]int{start + len(line) - len(strings.TrimLeft(line, " \t")), end})
+			inComment = !strings.HasSuffix(trimmed, ".")
+			atCommandStart = !inComment
+
+		default:
+			live := []byte(line)
+			for i := 0; i < len(line); i++ {
+				switch c := line[i]; {
+				case c == '"' || c == '\'':
+					if closing := strings.IndexByte(line[i+1:], c); closing != -1 {
+						i += closing + 1
+					} else {
+						i = len(line)
+					}
+				case c == '/' && i+1 < len(line) && line[i+1] == '*':
+					commentEnd := end - start
+					if closing := strings.Index(line[i+2:], "*/"); closing != -1 {
+						commentEnd = i + 2 + closing + 2
+					}
+					spans = append(spans, [2]int{start + i, start + commentEnd})
+					blankRange(live, i, commentEnd)
+					i = commentEnd - 1
+				}
+			}
+			atCommandStart = strings.HasSuffix(strings.TrimSpace(string(live)), ".")
+		}
+	}
+
+	return spans
+}
+
+// StripSPSSComments ... replace every SPSS comment in a given string with spaces
+//
+// Newlines are retained so that offsets and line numbers into the stripped
+// string match those of the original.
+func StripSPSSComments(contents string) string {
+	stripped := []byte(contents)
+	for _, span := range spssCommentSpans(contents) {
+		blankRange(stripped, span[0], span[1])
+	}
+	return string(stripped)
+}
+
+// ParseStringForSPSSComments ... obtain the |*|, |COMMENT| and |/* */| comments from a given SPSS string
+func ParseStringForSPSSComments(contents string) []model.Comment {
+
+	comments := make([]model.Comment, 0)
+
+	for _, span := range spssCommentSpans(contents) {
+
+		text := contents[span[0]:span[1]]
+		switch {
+		case strings.HasPrefix(text, "/*"):
+			text = strings.TrimSuffix(strings.TrimPrefix(text, "/*"), "*/")
+		case strings.HasPrefix(text, "*"):
+			text = strings.TrimLeft(text, "*")
+		default:
+			text = spssCommentCommandRegex.ReplaceAllString(text, "")
+		}
+
+		// the lines of a comment are joined, as are those of SAS comments
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimSpace(line)
+		}
+		text = strings.TrimSuffix(strings.Join(lines, " "), ".")
+
+		newComment := commentFromRaw(model.RawComment{LineNum: LineNumberAt(contents, span[0]), Text: text})
+		if newComment.Keyword == "" && newComment.Text == "" {
+			continue
+		}
+		comments = append(comments, newComment)
+	}
+
+	return comments
+}
+
+// SplitSPSSCommands ... split a string of SPSS syntax, with comments already stripped, into its commands
+//
+// A command ends at a period that ends a line, or at a blank line. The
+// terminating period is not part of the text of the command.
+func SplitSPSSCommands(code string) []Statement {
+
+	commands := make([]Statement, 0)
+	start := -1
+	offset := 0
+
+	for _, line := range strings.SplitAfter(code, "\n") {
+
+		lineStart := offset
+		offset += len(line)
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" {
+			if start != -1 {
+				commands = append(commands, Statement{Offset: start, Text: strings.TrimSpace(code[start:lineStart])})
+				start = -1
+			}
+			continue
+		}
+
+		if start == -1 {
+			start = lineStart + len(line) - len(strings.TrimLeft(line, " \t"))
+		}
+		if strings.HasSuffix(trimmed, ".") {
+			text := strings.TrimSuffix(strings.TrimSpace(code[start:offset]), ".")
+			commands = append(commands, Statement{Offset: start, Text: strings.TrimSpace(text)})
+			start = -1
+		}
+	}
+	if start != -1 {
+		commands = append(commands, Statement{Offset: start, Text: strings.TrimSpace(code[start:])})
+	}
+
+	return commands
+}
+
+// ParseStringForSPSSDependencies ... obtain the included files, file handles and host commands of an SPSS string
+func ParseStringForSPSSDependencies(contents string) []model.Dependency {
+
+	dependencies := make([]model.Dependency, 0)
+	code := StripSPSSComments(contents)
+
+	for _, command := range SplitSPSSCommands(code) {
+
+		lineNum := LineNumberAt(code, command.Offset)
+
+		if match := spssIncludeRegex.FindStringSubmatch(command.Text); match != nil {
+			dependencies = append(dependencies, model.Dependency{
+				LineNum:   lineNum,
+				Path:      unquoteSPSSString(match[2]),
+				Statement: strings.ToLower(match[1]),
+				Kind:      model.DependencyScript,
+			})
+		}
+
+		if match := spssFileHandleRegex.FindStringSubmatch(command.Text); match != nil {
+			dependencies = append(dependencies, model.Dependency{
+				LineNum:   lineNum,
+				Path:      unquoteSPSSString(match[2]),
+				Statement: "file handle",
+				Kind:      model.DependencyReference,
+				Name:      match[1],
+			})
+		}
+
+		// each quoted string of a host command is a line of the command
+		if match := spssHostRegex.FindStringSubmatch(command.Text); match != nil {
+			lines := make([]string, 0)
+			for _, str := range quotedStringRegex.FindAllString(match[1], -1) {
+				lines = append(lines, unquoteSPSSString(str))
+			}
+			dependencies = append(dependencies, model.Dependency{
+				LineNum:   lineNum,
+				Path:      strings.Join(lines, "; "),
+				Statement: "host",
+				Kind:      model.DependencyCommand,
+			})
+		}
+	}
+
+	return dependencies
+}
+
+// unquoteSPSSString ... obtain the contents of a quoted SPSS string, or the string itself if it is not quoted
+func unquoteSPSSString(str string) string {
+	if len(str) >= 2 && (str[0] == '"' || str[0] == '\'') && str[len(str)-1] == str[0] {
+		return str[1 : len(str)-1]
+	}
+	return str
+}
+
+// spssDataCommand ... obtain the name of an SPSS command that reads or writes datasets, or blank if it is not one
+func spssDataCommand(text string) string {
+	words := strings.Fields(strings.ToLower(text))
+	for _, name := range []string{"get sas", "get stata", "get data", "get", "import", "match files", "add files",
+		"update", "save translate", "save", "xsave", "export", "aggregate", "new file"} {
+		pieces := strings.Fields(name)
+		if len(words) >= len(pieces) && strings.Join(words[:len(pieces)], " ") == name {
+			return name
+		}
+	}
+	return ""
+}
+
+// ParseStringForSPSSLineage ... obtain the datasets an SPSS string reads and writes
+//
+// As in Stata, each save or export is a step whose inputs are all of the
+// datasets read or matched since the active dataset was last replaced.
+func ParseStringForSPSSLineage(contents string) []model.DatasetStep {
+
+	steps := make([]model.DatasetStep, 0)
+	code := StripSPSSComments(contents)
+
+	// datasets making up the active dataset, along with whether they have
+	// been written out since they were read in
+	inputs := make([]string, 0)
+	unsaved := false
+	loadStep := model.DatasetStep{}
+
+	// record the datasets that were read but never written
+	finishReads := func() {
+		if unsaved && len(inputs) > 0 {
+			loadStep.Inputs = inputs
+			steps = append(steps, loadStep)
+		}
+		unsaved = false
+	}
+
+	for _, command := range SplitSPSSCommands(code) {
+
+		name := spssDataCommand(command.Text)
+		if name == "" {
+			continue
+		}
+		lineNum := LineNumberAt(code, command.Offset)
+		endLineNum := LineNumberAt(code, command.Offset+len(command.Text))
+
+		// the active dataset, given as |*|, is not a file
+		files := make([]string, 0)
+		active := false
+		for _, match := range spssFileRegex.FindAllStringSubmatch(command.Text, -1) {
+			if match[2] == "*" {
+				active = true
+				continue
+			}
+			files = model.AppendUnique(files, unquoteSPSSString(match[2]))
+		}
+
+		switch name {
+
+		case "new file":
+			finishReads()
+			inputs = make([]string, 0)
+
+		case "get", "get sas", "get stata", "get data", "import":
+			if len(files) < 1 {
+				continue
+			}
+			finishReads()
+			inputs = files[:1]
+			unsaved = true
+			loadStep = model.DatasetStep{LineNum: lineNum, EndLineNum: endLineNum, Step: name}
+
+		case "match files", "add files", "update":
+			if !active {
+				finishReads()
+				inputs = make([]string, 0)
+				loadStep = model.DatasetStep{LineNum: lineNum, EndLineNum: endLineNum, Step: name}
+			}
+			inputs = model.AppendUnique(inputs, files...)
+			unsaved = true
+
+		default:
+			if len(files) < 1 {
+				continue
+			}
+			step := model.DatasetStep{LineNum: lineNum, EndLineNum: endLineNum, Step: name,
+				Inputs: append([]string{}, inputs...), Outputs: files}
+			steps = append(steps, step)
+			unsaved = false
+		}
+	}
+	finishReads()
+
+	return steps
+}
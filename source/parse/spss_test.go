@@ -0,0 +1,71 @@
+package parse
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseStringForSPSSComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"asterisk", "* @note Reads the data.\nGET FILE='a.sav'.\n", []string{"@note |Reads the data"}},
+		{"continued asterisk", "* @note First line\n  second line.\nGET FILE='a.sav'.\n", []string{"@note |First line second line"}},
+		{"comment command", "COMMENT @stat Fit the model.\n", []string{"@stat |Fit the model"}},
+		{"ended by a blank line", "* @note No period\n\nGET FILE='a.sav'.\n", []string{"@note |No period"}},
+		{"block comment", "COMPUTE x = 1. /* @note Trailing */\n", []string{"@note |Trailing"}},
+		{"unclosed block comment", "COMPUTE x = 1. /* @note To the end\n", []string{"@note |To the end"}},
+		{"multiplication is not a comment", "COMPUTE x = a\n * b.\n", []string{}},
+		{"comment in string", "TITLE 'a /* b */'.\n", []string{}},
+		{"plain comment", "* Not a keyword.\n", []string{"|Not a keyword"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, cmt := range ParseStringForSPSSComments(tt.contents) {
+				got = append(got, cmt.Keyword+"|"+cmt.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForSPSSComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringForSPSSDependencies(t *testing.T) {
+	contents := "* INCLUDE 'commented.sps'.\n" +
+		"INCLUDE 'setup.sps'.\n" +
+		"INSERT FILE=\"recode.sps\" CD=YES.\n" +
+		"FILE HANDLE data /NAME='/studies/data'.\n" +
+		"HOST COMMAND=['mkdir out' 'cp a out'].\n"
+	got := make([]string, 0)
+	for _, dependency := range ParseStringForSPSSDependencies(contents) {
+		got = append(got, strings.Join([]string{dependency.Kind, dependency.Statement, dependency.Name, dependency.Path}, "|"))
+	}
+	want := []string{"script|include||setup.sps", "script|insert||recode.sps", "reference|file handle|data|/studies/data",
+		"command|host||mkdir out; cp a out"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStringForSPSSDependencies() = %q, want %q", got, want)
+	}
+}
+
+func TestParseStringForSPSSLineage(t *testing.T) {
+	contents := "GET FILE='cohort.sav'\n  /KEEP=id age.\n" +
+		"MATCH FILES /FILE=* /TABLE='sites.sav' /BY site.\n" +
+		"SAVE OUTFILE='analysis.sav'.\n" +
+		"AGGREGATE OUTFILE=* /BREAK=site.\n" +
+		"SAVE TRANSLATE OUTFILE='analysis.csv' /TYPE=CSV.\n\n" +
+		"GET SAS DATA='visits.sas7bdat'.\n"
+	got := make([]string, 0)
+	for _, step := range ParseStringForSPSSLineage(contents) {
+		got = append(got, strings.Join([]string{step.Step, strings.Join(step.Inputs, ","), strings.Join(step.Outputs, ",")}, "|"))
+	}
+	want := []string{"save|cohort.sav,sites.sav|analysis.sav", "save translate|cohort.sav,sites.sav|analysis.csv",
+		"get sas|visits.sas7bdat|"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStringForSPSSLineage() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,307 @@
+/*
+ * Functions for reading the comments, included scripts and tables of SQL scripts
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// a table name, optionally qualified by its schema and quoted in any of the usual ways
+const sqlName = "((?:[a-zA-Z_#@][a-zA-Z0-9_$#@]*|\"[^\"]+\"|\\[[^\\]]+\\]|`[^`]+`)(?:\\.(?:[a-zA-Z_][a-zA-Z0-9_$]*|\"[^\"]+\"|\\[[^\\]]+\\]|`[^`]+`))*)"
+
+var (
+	// matches the |\i file|, |@@file|, |SOURCE file| and such commands of the SQL clients, which run another script,
+	// though the |@| and some of the words are just as likely to start a line of code, see sqlIncludes
+	sqlIncludeRegex = regexp.MustCompile("(?mi)^[ \\t]*(?:(\\\\ir|\\\\i|\\\\include_relative|\\\\include|\\\\\\.|\\.read|source|start)[ \\t]+|(@@|@))[ \\t]*('[^'\\n]*'|\"[^\"\\n]*\"|[^\\s;]+)[^\\n]*$")
+
+	// matches the |\! command| and |HOST command| commands of the SQL clients, which run an external command
+	sqlCommandRegex = regexp.MustCompile("(?mi)^[ \\t]*(\\\\!|host)[ \\t]+([^\\n]+)$")
+
+	// matches the |GO| and |/| lines that end a batch of statements, as a semicolon would
+	sqlBatchEndRegex = regexp.MustCompile("(?mi)^[ \\t]*(?:go|/)[ \\t]*$")
+
+	// matches the table or view created by a |CREATE| statement
+	sqlCreateRegex = regexp.MustCompile("(?is)^create\\s+(?:or\\s+replace\\s+)?(?:(?:global|local)\\s+)?(?:(?:temp|temporary|unlogged|materialized)\\s+)?(table|view)\\s+(?:if\\s+not\\s+exists\\s+)?" + sqlName)
+
+	// matches the table written by an |INSERT INTO|, |MERGE INTO|, |UPDATE| or |SELECT ... INTO| statement
+	sqlInsertRegex     = regexp.MustCompile("(?is)\\binsert\\s+(?:overwrite\\s+)?(?:into\\s+|table\\s+)?" + sqlName)
+	sqlMergeRegex      = regexp.MustCompile("(?is)\\bmerge\\s+into\\s+" + sqlName + ".*?\\busing\\s+" + sqlName)
+	sqlUpdateRegex     = regexp.MustCompile("(?is)^update\\s+" + sqlName)
+	sqlSelectIntoRegex = regexp.MustCompile("(?is)^select\\b.*?\\binto\\s+(?:(?:temp|temporary|unlogged|table)\\s+)*" + sqlName + "\\s+from\\b")
+
+	// matches the start of a |WITH| clause, or of a further common table expression of one, up to the parenthesis of its query
+	sqlCTEStartRegex = regexp.MustCompile("(?is)^(?:with(?:\\s+recursive)?\\s+|,\\s*)[a-zA-Z_][a-zA-Z0-9_]*\\s*(?:\\([^()]*\\)\\s*)?as\\s*(?:(?:not\\s+)?materialized\\s*)?\\(")
+
+	// matches the names of the common table expressions of a |WITH| clause, which are not tables
+	sqlCTERegex = regexp.MustCompile("(?is)(?:\\bwith(?:\\s+recursive)?|,)\\s*([a-zA-Z_][a-zA-Z0-9_]*)\\s*(?:\\([^()]*\\)\\s*)?as\\s*\\(")
+
+	// matches the opening |$tag$| of a PostgreSQL dollar quoted string, such as the body of a function
+	sqlDollarQuoteRegex = regexp.MustCompile("^\\$(?:[a-zA-Z_][a-zA-Z0-9_]*)?\\$")
+
+	// matches the functions whose arguments may contain a FROM that does not name a table
+	sqlFromFunctionRegex = regexp.MustCompile("(?i)\\b(?:extract|substring|trim|position|overlay)\\s*\\([^()]*\\)")
+)
+
+// sqlCommentSpans ... obtain the [start, end) offsets of each |--| and |/* */| comment of a given SQL string
+//
+// Strings quoted by |'|, |"| or backticks, along with |$tag$| dollar quoted
+// strings, are skipped so that comment delimiters inside of them are ignored.
+func sqlCommentSpans(contents string) [][2]int {
+
+	spans := make([][2]int, 0)
+
+	for i := 0; i < len(contents); i++ {
+		c := contents[i]
+
+		switch {
+
+		case c == '-' && strings.HasPrefix(contents[i:], "--"):
+			end := strings.IndexByte(contents[i:], '\n')
+			if end == -1 {
+				end = len(contents)
+			} else {
+				end += i
+			}
+			spans = append(spans, [2]int{i, end})
+			i = end
+
+		case c == '/' && strings.HasPrefix(contents[i:], "/*"):
+			end := strings.Index(contents[i+2:], "*/")
+			if end == -1 {
+				end = len(contents)
+			} else {
+				end += i + 4
+			}
+			spans = append(spans, [2]int{i, end})
+			i = end - 1
+
+		// a doubled quote inside of a string is just a quote, which the loop handles by re-entering the string
+		case c == '\'' || c == '"' || c == '`':
+			end := strings.IndexByte(contents[i+1:], c)
+			if end == -1 {
+				i = len(contents)
+			} else {
+				i += end + 1
+			}
+
+		case c == '$':
+			if tag := sqlDollarQuoteRegex.FindString(contents[i:]); tag != "" {
+				if closing := strings.Index(contents[i+len(tag):], tag); closing != -1 {
+					i += len(tag) + closing + len(tag) - 1
+				} else {
+					i = len(contents)
+				}
+			}
+		}
+	}
+
+	return spans
+}
+
+// StripSQLComments ... replace every SQL comment in a given string with spaces
+//
+// Newlines are retained so that offsets and line numbers into the stripped
+// string match those of the original.
+func StripSQLComments(contents string) string {
+	stripped := []byte(contents)
+	for _, span := range sqlCommentSpans(contents) {
+		blankRange(stripped, span[0], span[1])
+	}
+	return string(stripped)
+}
+
+// ParseStringForSQLComments ... obtain the |--| and |/* */| comments from a given SQL string
+//
+// Consecutive lines of |--| comments form a single comment, as set out by
+// lineComments, whereas each |/* */| comment is one of its own.
+func ParseStringForSQLComments(contents string) []model.Comment {
+
+	comments := newLineComments()
+
+	for _, span := range sqlCommentSpans(contents) {
+
+		lineNum := LineNumberAt(contents, span[0])
+		lineStart := strings.LastIndexByte(contents[:span[0]], '\n') + 1
+		wholeLine := strings.TrimSpace(contents[lineStart:span[0]]) == ""
+		text := contents[span[0]:span[1]]
+
+		if strings.HasPrefix(text, "/*") {
+			lines := strings.Split(strings.TrimSuffix(strings.TrimLeft(text, "/*"), "*/"), "\n")
+			for i, line := range lines {
+				lines[i] = strings.TrimSpace(line)
+			}
+			comments.end()
+			if newComment := commentFromRaw(model.RawComment{LineNum: lineNum, Text: strings.Join(lines, " ")}); newComment.Keyword != "" || newComment.Text != "" {
+				comments.comments = append(comments.comments, newComment)
+			}
+			continue
+		}
+
+		comments.add(lineNum, wholeLine, "--", strings.TrimSpace(strings.TrimLeft(text, "-")), false)
+	}
+
+	return comments.comments
+}
+
+// sqlIncludes ... obtain the submatch indexes of the commands of a SQL string that run another script
+//
+// The words of the MySQL and SQL*Plus commands, along with the |@| that also
+// starts the parameters of T-SQL, are only taken for such a command when they
+// are followed by something that looks like a file.
+func sqlIncludes(code string) [][]int {
+	includes := make([][]int, 0)
+	for _, sindex := range sqlIncludeRegex.FindAllStringSubmatchIndex(code, -1) {
+		switch sqlIncludeStatement(code, sindex) {
+		case "source", "start", "@", "@@":
+			if !strings.ContainsAny(code[sindex[6]:sindex[7]], "./\\") {
+				continue
+			}
+		}
+		includes = append(includes, sindex)
+	}
+	return includes
+}
+
+// sqlIncludeStatement ... obtain the command of a match of sqlIncludeRegex, e.g. |\i| or |@@|
+func sqlIncludeStatement(code string, sindex []int) string {
+	if sindex[2] != -1 {
+		return strings.ToLower(code[sindex[2]:sindex[3]])
+	}
+	return code[sindex[4]:sindex[5]]
+}
+
+// ParseStringForSQLDependencies ... obtain the scripts run and external commands of a SQL string
+func ParseStringForSQLDependencies(contents string) []model.Dependency {
+
+	dependencies := make([]model.Dependency, 0)
+	code := StripSQLComments(contents)
+
+	for _, sindex := range sqlIncludes(code) {
+
+		statement := sqlIncludeStatement(code, sindex)
+		path := strings.Trim(code[sindex[6]:sindex[7]], "'\"")
+
+		dependencies = append(dependencies, model.Dependency{
+			LineNum:   LineNumberAt(code, sindex[0]),
+			Path:      path,
+			Statement: statement,
+			Kind:      model.DependencyScript,
+		})
+	}
+
+	for _, sindex := range sqlCommandRegex.FindAllStringSubmatchIndex(code, -1) {
+		dependencies = append(dependencies, model.Dependency{
+			LineNum:   LineNumberAt(code, sindex[0]),
+			Path:      strings.TrimSpace(code[sindex[4]:sindex[5]]),
+			Statement: strings.ToLower(code[sindex[2]:sindex[3]]),
+			Kind:      model.DependencyCommand,
+		})
+	}
+
+	return dependencies
+}
+
+// sqlSkipWith ... obtain the statement following the |WITH| clause a given statement starts with, if any
+func sqlSkipWith(text string) string {
+	for {
+		sindex := sqlCTEStartRegex.FindStringIndex(text)
+		if sindex == nil {
+			return text
+		}
+		closing := matchingParen(text[sindex[1]-1:])
+		if closing == -1 {
+			return text
+		}
+		text = strings.TrimSpace(text[sindex[1]+closing:])
+	}
+}
+
+// sqlTableName ... obtain a table name without the quotes or brackets around each of its parts
+func sqlTableName(name string) string {
+	return strings.NewReplacer("\"", "", "[", "", "]", "", "`", "").Replace(name)
+}
+
+// ParseStringForSQLLineage ... obtain the tables each statement of a SQL string reads and writes
+//
+// Tables are read by the FROM, JOIN and USING clauses of a statement, and
+// written by CREATE TABLE, CREATE VIEW, INSERT, UPDATE, MERGE and SELECT INTO.
+// Queries that only read tables are kept as |select| steps.
+func ParseStringForSQLLineage(contents string) []model.DatasetStep {
+
+	steps := make([]model.DatasetStep, 0)
+	code := StripSQLComments(contents)
+
+	// the commands of the SQL clients are not statements, and batches end as statements do
+	blanked := []byte(code)
+	for _, sindex := range sqlIncludes(code) {
+		blankRange(blanked, sindex[0], sindex[1])
+	}
+	code = string(blanked)
+	code = sqlCommandRegex.ReplaceAllStringFunc(code, func(str string) string { return strings.Repeat(" ", len(str)) })
+	code = sqlBatchEndRegex.ReplaceAllStringFunc(code, func(str string) string { return ";" + strings.Repeat(" ", len(str)-1) })
+
+	for _, statement := range SplitSASStatements(code) {
+
+		text := strings.Join(strings.Fields(statement.Text), " ")
+		if text == "" {
+			continue
+		}
+		step := model.DatasetStep{
+			LineNum:    LineNumberAt(code, statement.Offset),
+			EndLineNum: LineNumberAt(code, statement.Offset+len(strings.TrimSpace(statement.Text))),
+		}
+
+		lower := strings.ToLower(text)
+		if match := sqlCreateRegex.FindStringSubmatch(text); match != nil {
+			step.Step = "create " + strings.ToLower(match[1])
+			step.Outputs = []string{sqlTableName(match[2])}
+		} else if match := sqlMergeRegex.FindStringSubmatch(text); match != nil {
+			step.Step = "merge"
+			step.Outputs = []string{sqlTableName(match[1])}
+			step.Inputs = []string{sqlTableName(match[2])}
+		} else if match := sqlInsertRegex.FindStringSubmatch(text); match != nil {
+			step.Step = "insert"
+			step.Outputs = []string{sqlTableName(match[1])}
+		} else if match := sqlUpdateRegex.FindStringSubmatch(text); match != nil {
+			step.Step = "update"
+			step.Outputs = []string{sqlTableName(match[1])}
+		} else if match := sqlSelectIntoRegex.FindStringSubmatch(sqlSkipWith(text)); match != nil {
+			step.Step = "select into"
+			step.Outputs = []string{sqlTableName(match[1])}
+		} else if strings.HasPrefix(lower, "select") || strings.HasPrefix(lower, "with") {
+			step.Step = "select"
+		} else {
+			continue
+		}
+
+		// the tables of common table expressions only exist within the statement
+		expressions := make(map[string]bool)
+		for _, match := range sqlCTERegex.FindAllStringSubmatch(text, -1) {
+			expressions[strings.ToLower(match[1])] = true
+		}
+
+		// each search resumes after the tables, as the keyword ending a list may be a JOIN starting another
+		reads := sqlFromFunctionRegex.ReplaceAllString(text, "")
+		for sindex := sqlInputRegex.FindStringSubmatchIndex(reads); sindex != nil; sindex = sqlInputRegex.FindStringSubmatchIndex(reads) {
+			for _, table := range splitTopLevel(reads[sindex[2]:sindex[3]], ',') {
+				fields := strings.Fields(table)
+				if len(fields) < 1 || expressions[strings.ToLower(fields[0])] {
+					continue
+				}
+				step.Inputs = model.AppendUnique(step.Inputs, sqlTableName(fields[0]))
+			}
+			reads = reads[sindex[3]:]
+		}
+
+		if len(step.Inputs) > 0 || len(step.Outputs) > 0 {
+			steps = append(steps, step)
+		}
+	}
+
+	return steps
+}
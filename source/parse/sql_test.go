@@ -0,0 +1,106 @@
+package parse
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestParseStringForSQLComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"keyword", "-- @note Loads the registry\nselect 1;\n", []string{"@note |Loads the registry"}},
+		{"continued keyword", "-- @stat Counted per patient\n--   and per site\n", []string{"@stat |Counted per patient and per site"}},
+		{"keyword splits block", "-- Intro\n-- @note First\n-- @note Second\n", []string{"|Intro", "@note |First", "@note |Second"}},
+		{"trailing comment", "select 1; -- @note Trailing\n-- @note Next\n", []string{"@note |Trailing", "@note |Next"}},
+		{"block comment", "/* @note Spans\n   two lines */\n", []string{"@note |Spans two lines"}},
+		{"dashes in string", "select '-- not', \"/* not */\" from t; -- real\n", []string{"|real"}},
+		{"dollar quoted", "create function f() returns int as $body$ -- not\n$body$; -- real\n", []string{"|real"}},
+		{"empty comments", "--\n/* */\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, cmt := range ParseStringForSQLComments(tt.contents) {
+				got = append(got, cmt.Keyword+"|"+cmt.Text)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForSQLComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringForSQLDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"psql", "\\i setup.sql\n\\ir 'lib/views.sql'\n", []string{"script \\i setup.sql", "script \\ir lib/views.sql"}},
+		{"sqlplus", "@@grants.sql\n@/opt/load.sql\nSTART cleanup.sql\n", []string{"script @@ grants.sql", "script @ /opt/load.sql", "script start cleanup.sql"}},
+		{"mysql", "SOURCE schema.sql;\n", []string{"script source schema.sql"}},
+		{"sqlite", ".read seed.sql\n", []string{"script .read seed.sql"}},
+		{"not a file", "start transaction;\n", []string{}},
+		{"T-SQL parameters", "create procedure load_visits\n    @StartDate date,\n    @EndDate date\nas select 1;\n", []string{}},
+		{"command", "\\! gzip dump.csv\nHOST rm -f out.lst\n", []string{"command \\! gzip dump.csv", "command host rm -f out.lst"}},
+		{"commented out", "-- \\i setup.sql\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, dependency := range ParseStringForSQLDependencies(tt.contents) {
+				got = append(got, dependency.Kind+" "+dependency.Statement+" "+dependency.Path)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForSQLDependencies() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringForSQLLineage(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"create as select", "create table if not exists stage.cohort as\nselect * from raw.registry r\njoin raw.visits v on r.id = v.id;\n",
+			[]string{"1-3 create table raw.registry,raw.visits > stage.cohort"}},
+		{"insert", "INSERT INTO \"Summary\" (id, n)\nSELECT id, count(*) FROM cohort GROUP BY id;\n",
+			[]string{"1-2 insert cohort > Summary"}},
+		{"view", "create or replace view [dbo].[adults] as select * from [dbo].[cohort] where age >= 18\nGO\n",
+			[]string{"1-1 create view dbo.cohort > dbo.adults"}},
+		{"select into", "select id into #tmp from cohort;\n", []string{"1-1 select into cohort > #tmp"}},
+		{"select into after with", "WITH x AS (select * from t2) select * into t3 from x;\n", []string{"1-1 select into t2 > t3"}},
+		{"select into after recursive with", "with recursive n (i) as (select 1 union all select i + 1 from n where i < 5),\n" +
+			"m as (select i from n)\nselect * into t4 from m;\n", []string{"1-3 select into  > t4"}},
+		{"merge", "merge into target t using staging s on t.id = s.id when matched then update set n = s.n;\n",
+			[]string{"1-1 merge staging > target"}},
+		{"update", "update cohort set flag = 1 where id in (select id from excluded);\n", []string{"1-1 update excluded > cohort"}},
+		{"common table expression", "with recent as (select * from visits), counts as (select id from recent)\nselect * from counts join sites on 1=1;\n",
+			[]string{"1-2 select visits,sites > "}},
+		{"functions and subqueries", "select extract(year from visit_date) from (select * from visits) v;\n",
+			[]string{"1-1 select visits > "}},
+		{"client commands", "\\i setup.sql\nselect * from a;\n", []string{"2-2 select a > "}},
+		{"T-SQL parameters", "create procedure load_visits\n    @Since date\nas\ninsert into visits select * from raw_visits where d >= @Since;\n",
+			[]string{"1-4 insert raw_visits > visits"}},
+		{"no tables", "set search_path = stage;\nselect 1;\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, step := range ParseStringForSQLLineage(tt.contents) {
+				got = append(got, strconv.Itoa(step.LineNum)+"-"+strconv.Itoa(step.EndLineNum)+" "+step.Step+" "+
+					strings.Join(step.Inputs, ",")+" > "+strings.Join(step.Outputs, ","))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForSQLLineage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
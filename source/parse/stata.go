@@ -0,0 +1,278 @@
+/*
+ * Functions for reading Stata program definitions and their syntax statements
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches the start of a |program define name| or |program name| definition
+	programDefinitionRegex = regexp.MustCompile("(?m)^[ \\t]*(?:(?:capture|capt?|quietly|qui)[ \\t]*:?[ \\t]+)?pr(?:o|og|ogr|ogra|ogram)?[ \\t]+([a-zA-Z_][a-zA-Z0-9_]*)(?:[ \\t]+([a-zA-Z_][a-zA-Z0-9_]*))?[ \\t]*(?:,([^\\n]*))?$")
+
+	// matches the |end| line that closes a program definition
+	programEndRegex = regexp.MustCompile("(?m)^[ \\t]*end[ \\t]*$")
+
+	// matches the |syntax| statement of a program
+	syntaxRegex = regexp.MustCompile("(?m)^[ \\t]*syntax[ \\t]+([^\\n]*)$")
+
+	// matches the |///| continuation at the end of a line
+	continuationRegex = regexp.MustCompile("[ \\t]+///[^\\n]*\\n")
+
+	// program subcommands that do not define a program
+	programSubcommands = map[string]bool{"dir": true, "drop": true, "list": true, "describe": true}
+
+	// elements of a syntax statement that describe the variable list
+	syntaxVarlists = []string{"varlist", "varname", "newvarlist", "newvarname", "namelist", "name", "anything"}
+
+	// elements of a syntax statement that describe the allowed weights
+	syntaxWeights = []string{"fweight", "aweight", "pweight", "iweight"}
+)
+
+// IsStataFile ... whether a given path is a Stata do-file or ado-file
+func IsStataFile(path string) bool {
+	return strings.HasSuffix(path, ".do") || strings.HasSuffix(path, ".ado")
+}
+
+// ParseStringForPrograms ... obtain all of the program definitions from a given Stata string
+func ParseStringForPrograms(filename string, contents string) []model.Macro {
+
+	programs := make([]model.Macro, 0)
+	code := StripStataComments(contents)
+	searchFrom := 0
+
+	for {
+		sindex := programDefinitionRegex.FindStringSubmatchIndex(code[searchFrom:])
+		if sindex == nil {
+			break
+		}
+		for i := range sindex {
+			if sindex[i] != -1 {
+				sindex[i] += searchFrom
+			}
+		}
+		searchFrom = sindex[1]
+
+		name := code[sindex[2]:sindex[3]]
+		if programSubcommands[strings.ToLower(name)] {
+			continue
+		}
+
+		// handle the |program define name| form, where define may be abbreviated
+		if sindex[4] != -1 {
+			if !isAbbreviationOf(strings.ToLower(name), "define", 2) {
+				continue
+			}
+			name = code[sindex[4]:sindex[5]]
+		}
+
+		program := model.Macro{
+			Name:     name,
+			Filename: filename,
+			LineNum:  LineNumberAt(code, sindex[0]),
+			Summary:  PrecedingComment(contents, LineNumberAt(code, sindex[0])),
+		}
+		if sindex[6] != -1 {
+			program.Properties = strings.TrimSpace(code[sindex[6]:sindex[7]])
+		}
+
+		// find the end of the program, defaulting to the end of the file
+		end := len(code)
+		if eindex := programEndRegex.FindStringIndex(code[sindex[1]:]); eindex != nil {
+			end = sindex[1] + eindex[1]
+		}
+		program.EndLineNum = LineNumberAt(code, end)
+
+		// obtain the syntax statement, joining any continued lines first
+		body := continuationRegex.ReplaceAllString(contents[sindex[1]:end], " ")
+		body = StripStataComments(body)
+		if match := syntaxRegex.FindStringSubmatch(body); match != nil {
+			program.Syntax = ParseSyntaxStatement(match[1])
+			for _, option := range program.Syntax.Options {
+				program.Params = append(program.Params, model.MacroParam{
+					Name:    strings.ToLower(option.Name),
+					Default: option.Default,
+					Keyword: true,
+				})
+			}
+		}
+
+		programs = append(programs, program)
+		searchFrom = end
+	}
+
+	return programs
+}
+
+// isAbbreviationOf ... whether a word is an abbreviation of the given command of at least the given length
+func isAbbreviationOf(word string, command string, minimum int) bool {
+	return len(word) >= minimum && strings.HasPrefix(command, word)
+}
+
+// ParseSyntaxStatement ... convert the text following |syntax| into its elements
+func ParseSyntaxStatement(text string) *model.StataSyntax {
+
+	text = strings.Join(strings.Fields(text), " ")
+	syntax := &model.StataSyntax{Text: "syntax " + text}
+
+	for i := 0; i < len(text); i++ {
+
+		if isSpace(text[i]) {
+			continue
+		}
+
+		// obtain the next element, noting whether it is optional
+		element := ""
+		optional := false
+		switch text[i] {
+		case '[':
+			end := matchingBracket(text[i:])
+			if end == -1 {
+				end = len(text) - i
+			}
+			element = strings.TrimSpace(text[i+1 : i+end])
+			optional = true
+			i += end
+		case ',':
+			element = text[i:]
+			i = len(text)
+		default:
+			end := i
+			depth := 0
+			for ; end < len(text); end++ {
+				if text[end] == '(' {
+					depth++
+				} else if text[end] == ')' {
+					depth--
+				} else if depth == 0 && (isSpace(text[end]) || text[end] == '[' || text[end] == ',') {
+					break
+				}
+			}
+			element = text[i:end]
+			i = end - 1
+		}
+
+		// the options are always last
+		if strings.HasPrefix(element, ",") {
+			syntax.Options = parseSyntaxOptions(element[1:], !optional)
+			continue
+		}
+
+		lowercase := strings.ToLower(element)
+		keyword := strings.TrimRight(strings.SplitN(lowercase, "(", 2)[0], "/")
+		words := strings.Fields(keyword)
+		requirement := "required"
+		if optional {
+			requirement = "optional"
+		}
+
+		switch {
+		case keyword == "if":
+			syntax.If = requirement
+		case keyword == "in":
+			syntax.In = requirement
+		case keyword == "using":
+			syntax.Using = requirement
+		case strings.HasPrefix(keyword, "=") || keyword == "exp":
+			syntax.Exp = requirement
+		case len(words) > 0 && containsString(syntaxWeights, words[0]):
+			// the weights allowed are listed together, e.g. |[fweight pweight]|
+			for _, weight := range words {
+				if containsString(syntaxWeights, weight) {
+					syntax.Weights = append(syntax.Weights, weight)
+				}
+			}
+		case containsString(syntaxVarlists, keyword):
+			syntax.Varlist = element
+			syntax.VarlistRequirement = requirement
+		}
+	}
+
+	return syntax
+}
+
+// parseSyntaxOptions ... convert the options of a syntax statement into StataOption entries
+func parseSyntaxOptions(text string, required bool) []model.StataOption {
+
+	options := make([]model.StataOption, 0)
+	inBrackets := false
+
+	for _, piece := range splitTopLevel(strings.TrimSpace(text), ' ') {
+
+		piece = strings.TrimSpace(piece)
+		if piece == "" {
+			continue
+		}
+
+		// options given in square brackets are optional, even when the
+		// options as a whole are not
+		if strings.HasPrefix(piece, "[") {
+			inBrackets = true
+			piece = strings.TrimPrefix(piece, "[")
+		}
+		optional := inBrackets
+		if strings.HasSuffix(piece, "]") {
+			inBrackets = false
+			piece = strings.TrimSuffix(piece, "]")
+		}
+		if piece == "" {
+			continue
+		}
+
+		option := model.StataOption{Name: piece, Required: required && !optional}
+
+		// handle the |Name(type default)| form
+		if paren := strings.Index(piece, "("); paren != -1 {
+			option.Name = piece[:paren]
+			spec := strings.Fields(strings.TrimSuffix(piece[paren+1:], ")"))
+			if len(spec) > 0 {
+				option.Type = strings.ToLower(spec[0])
+				option.Default = strings.Join(spec[1:], " ")
+			}
+		}
+
+		// the capitalized portion of an option is its minimal abbreviation
+		option.Abbreviation = strings.ToLower(strings.TrimRightFunc(option.Name, func(r rune) bool {
+			return r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_'
+		}))
+		if option.Abbreviation == "" || strings.ToUpper(option.Name) == option.Name {
+			option.Abbreviation = strings.ToLower(option.Name)
+		}
+
+		options = append(options, option)
+	}
+
+	return options
+}
+
+// matchingBracket ... obtain the index of the bracket closing the one that starts the string
+func matchingBracket(str string) int {
+	depth := 0
+	for i, c := range str {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// containsString ... whether a given list contains the given string
+func containsString(list []string, str string) bool {
+	for _, entry := range list {
+		if entry == str {
+			return true
+		}
+	}
+	return false
+}
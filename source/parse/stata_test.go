@@ -0,0 +1,91 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestParseStringForPrograms(t *testing.T) {
+	contents := "program drop _all\n" +
+		"* Summarise a variable\n" +
+		"capture program define summ_var, rclass\n" +
+		"  syntax varlist(numeric) [if] [in], ///\n" +
+		"    BY(varname) [Level(integer 95)]\n" +
+		"end\n" +
+		"pr de inner\n" +
+		"end\n" +
+		"program list\n" +
+		"program helper\n" +
+		"  display 1\n"
+	programs := ParseStringForPrograms("a.ado", contents)
+	got := make([]string, 0)
+	for _, program := range programs {
+		got = append(got, program.Name)
+	}
+	if want := []string{"summ_var", "inner", "helper"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseStringForPrograms() = %q, want %q", got, want)
+	}
+
+	summ := programs[0]
+	if summ.LineNum != 3 || summ.EndLineNum != 6 || summ.Summary != "Summarise a variable" || summ.Properties != "rclass" {
+		t.Errorf("ParseStringForPrograms() = %+v, want summ_var on lines 3 to 6 with its summary and properties", summ)
+	}
+	if summ.Syntax == nil || summ.Syntax.Varlist != "varlist(numeric)" || summ.Syntax.If != "optional" {
+		t.Errorf("ParseStringForPrograms() syntax = %+v, want the continued syntax statement", summ.Syntax)
+	}
+	wantParams := []model.MacroParam{{Name: "by", Keyword: true}, {Name: "level", Default: "95", Keyword: true}}
+	if !reflect.DeepEqual(summ.Params, wantParams) {
+		t.Errorf("ParseStringForPrograms() params = %+v, want %+v", summ.Params, wantParams)
+	}
+
+	if helper := programs[2]; helper.LineNum != 10 || helper.EndLineNum != 12 || helper.Syntax != nil {
+		t.Errorf("ParseStringForPrograms() = %+v, want helper running from line 10 to the end", helper)
+	}
+}
+
+func TestParseSyntaxStatement(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want model.StataSyntax
+	}{
+		{"empty brackets", "[]", model.StataSyntax{Text: "syntax []"}},
+		{"varlist only", "varlist", model.StataSyntax{Text: "syntax varlist", Varlist: "varlist", VarlistRequirement: "required"}},
+		{"qualifiers", "[varlist] if/ [in] using/ [=/exp]", model.StataSyntax{
+			Text:    "syntax [varlist] if/ [in] using/ [=/exp]",
+			Varlist: "varlist", VarlistRequirement: "optional",
+			If: "required", In: "optional", Using: "required", Exp: "optional",
+		}},
+		{"weights", "newvarname [fweight pweight]", model.StataSyntax{
+			Text:    "syntax newvarname [fweight pweight]",
+			Varlist: "newvarname", VarlistRequirement: "required",
+			Weights: []string{"fweight", "pweight"},
+		}},
+		{"optional options", "anything [, Replace GENerate(name) SAVing(string asis)]", model.StataSyntax{
+			Text:    "syntax anything [, Replace GENerate(name) SAVing(string asis)]",
+			Varlist: "anything", VarlistRequirement: "required",
+			Options: []model.StataOption{
+				{Name: "Replace", Abbreviation: "r"},
+				{Name: "GENerate", Abbreviation: "gen", Type: "name"},
+				{Name: "SAVing", Abbreviation: "sav", Type: "string", Default: "asis"},
+			},
+		}},
+		{"required options", "varname, BY(varlist) [NOLOG]", model.StataSyntax{
+			Text:    "syntax varname, BY(varlist) [NOLOG]",
+			Varlist: "varname", VarlistRequirement: "required",
+			Options: []model.StataOption{
+				{Name: "BY", Abbreviation: "by", Type: "varlist", Required: true},
+				{Name: "NOLOG", Abbreviation: "nolog"},
+			},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseSyntaxStatement(tt.text); !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("ParseSyntaxStatement() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
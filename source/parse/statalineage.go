@@ -0,0 +1,241 @@
+/*
+ * Functions for reading the datasets a Stata program reads and writes
+ */
+
+package parse
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+var (
+	// matches the prefixes that may precede a Stata command
+	stataPrefixRegex = regexp.MustCompile("^(?:(?:capture|capt?|quietly|qui|noisily|noi)\\s*:?\\s+)+")
+
+	// matches the |`name'| tempfile references
+	stataTempfileRegex = regexp.MustCompile("^`([a-zA-Z_][a-zA-Z0-9_]*)'$")
+)
+
+// Kinds of commands that affect the dataset in memory
+const (
+	stataLoad  = "load"
+	stataAdd   = "add"
+	stataWrite = "write"
+)
+
+// stataDataCommand ... obtain the kind of a Stata data command along with its full name
+func stataDataCommand(words []string) (string, string) {
+
+	if len(words) < 1 {
+		return "", ""
+	}
+	// the options may follow the command without a space, e.g. |save, replace|
+	command := strings.ToLower(strings.TrimSuffix(words[0], ","))
+
+	switch {
+	case isAbbreviationOf(command, "use", 1):
+		return stataLoad, "use"
+	case isAbbreviationOf(command, "save", 2):
+		return stataWrite, "save"
+	case command == "saveold":
+		return stataWrite, "saveold"
+	case command == "merge" || command == "joinby" || command == "cross":
+		return stataAdd, command
+	case isAbbreviationOf(command, "append", 3):
+		return stataAdd, "append"
+	case isAbbreviationOf(command, "insheet", 4) || command == "infile" || command == "infix":
+		return stataLoad, command
+	case isAbbreviationOf(command, "outsheet", 4):
+		return stataWrite, "outsheet"
+	case command == "import" || command == "export":
+		kind := stataLoad
+		if command == "export" {
+			kind = stataWrite
+		}
+		if len(words) > 1 {
+			command += " " + strings.ToLower(words[1])
+		}
+		return kind, command
+	}
+
+	return "", ""
+}
+
+// stataFileArguments ... obtain the, possibly quoted, files named in a Stata command
+//
+// Files follow the |using| keyword when present, otherwise the first argument
+// after the command itself is taken as the file.
+func stataFileArguments(text string, skip int, all bool) []string {
+
+	// options are not of interest
+	if comma := strings.Index(text, ","); comma != -1 && !strings.Contains(text[:comma], "\"") {
+		text = text[:comma]
+	}
+
+	words := strings.Fields(text)
+	rest := ""
+	for i, word := range words {
+		if strings.ToLower(word) == "using" {
+			rest = strings.Join(words[i+1:], " ")
+			break
+		}
+	}
+	if rest == "" {
+		if len(words) <= skip {
+			return nil
+		}
+		rest = strings.Join(words[skip:], " ")
+		all = false
+	}
+
+	files := make([]string, 0)
+	for rest != "" {
+		file, length := firstStataArgument(rest)
+		if file == "" {
+			break
+		}
+		files = append(files, file)
+		if !all {
+			break
+		}
+
+		// move past the argument just read, along with any quotes
+		rest = rest[length:]
+		rest = strings.TrimSpace(rest)
+		if strings.HasPrefix(rest, ",") {
+			break
+		}
+	}
+
+	return files
+}
+
+// ParseStringForStataLineage ... obtain the datasets a Stata string reads and writes
+//
+// Each save or export is a step whose inputs are all of the datasets loaded,
+// merged or appended since the dataset in memory was last replaced.
+func ParseStringForStataLineage(contents string) []model.DatasetStep {
+
+	steps := make([]model.DatasetStep, 0)
+	code := StripStataComments(contents)
+	macros := newStataMacros(code)
+	tempfiles := make(map[string]bool)
+
+	// datasets making up the one in memory, along with whether they have
+	// been written out since they were read in
+	inputs := make([]string, 0)
+	unsaved := false
+	current := ""
+	loadStep := model.DatasetStep{}
+
+	// record the datasets that were read but never written
+	finishReads := func() {
+		if unsaved && len(inputs) > 0 {
+			loadStep.Inputs = inputs
+			steps = append(steps, loadStep)
+		}
+		unsaved = false
+	}
+
+	for _, command := range SplitStataCommands(contents) {
+
+		macros.DefineUntil(command.Offset)
+		lineNum := LineNumberAt(code, command.Offset)
+
+		// skip prefixes such as |capture| or |quietly:|
+		text := stataPrefixRegex.ReplaceAllString(command.Text, "")
+		words := strings.Fields(text)
+		if len(words) < 1 {
+			continue
+		}
+
+		// tempfiles have no paths, so just note them for later
+		if isAbbreviationOf(strings.ToLower(words[0]), "tempfile", 5) {
+			for _, name := range words[1:] {
+				tempfiles[name] = true
+			}
+			continue
+		}
+
+		// clearing the dataset in memory
+		if strings.ToLower(words[0]) == "clear" {
+			finishReads()
+			inputs = make([]string, 0)
+			current = ""
+			continue
+		}
+
+		kind, name := stataDataCommand(words)
+		if kind == "" {
+			continue
+		}
+
+		// |import delimited| and the like have a subcommand before the file
+		skip := 1
+		if strings.Contains(name, " ") {
+			skip = 2
+		}
+
+		files := make([]string, 0)
+		for _, file := range stataFileArguments(text, skip, kind == stataAdd) {
+			files = append(files, stataDatasetName(macros.Resolve(file), name, tempfiles))
+		}
+
+		switch kind {
+
+		case stataLoad:
+			if len(files) < 1 {
+				continue
+			}
+			finishReads()
+			inputs = []string{files[0]}
+			current = files[0]
+			unsaved = true
+			loadStep = model.DatasetStep{LineNum: lineNum, EndLineNum: lineNum, Step: name}
+
+		case stataAdd:
+			inputs = model.AppendUnique(inputs, files...)
+			unsaved = true
+
+		case stataWrite:
+			// |save, replace| overwrites the dataset last used or saved
+			if len(files) < 1 {
+				if current == "" || name != "save" {
+					continue
+				}
+				files = []string{current}
+			}
+			step := model.DatasetStep{LineNum: lineNum, EndLineNum: lineNum, Step: name, Inputs: append([]string{}, inputs...), Outputs: files}
+			steps = append(steps, step)
+			unsaved = false
+			if name == "save" || name == "saveold" {
+				inputs = []string{files[0]}
+				current = files[0]
+			}
+		}
+	}
+	finishReads()
+
+	return steps
+}
+
+// stataDatasetName ... obtain the name of a dataset given to a Stata command, noting tempfiles
+func stataDatasetName(file string, command string, tempfiles map[string]bool) string {
+
+	if match := stataTempfileRegex.FindStringSubmatch(file); match != nil && tempfiles[match[1]] {
+		return "tempfile " + match[1]
+	}
+
+	// Stata assumes the .dta extension for its own datasets
+	if filepath.Ext(file) == "" && !strings.HasPrefix(command, "import") &&
+		!strings.HasPrefix(command, "export") && command != "insheet" && command != "outsheet" &&
+		command != "infile" && command != "infix" {
+		file += ".dta"
+	}
+
+	return file
+}
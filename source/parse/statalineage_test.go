@@ -0,0 +1,71 @@
+package parse
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestStataFileArguments(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		skip int
+		all  bool
+		want []string
+	}{
+		{"first argument", "use cohort, clear", 1, false, []string{"cohort"}},
+		{"using", "merge 1:1 id using visits, nogen", 1, true, []string{"visits"}},
+		{"quoted", "save \"out/final cohort\", replace", 1, false, []string{"out/final cohort"}},
+		{"compound quoted", "use `\"data/base line\"'", 1, false, []string{"data/base line"}},
+		{"several files", "append using a \"b c\" `\"d\"' e, force", 1, true, []string{"a", "b c", "d", "e"}},
+		{"subcommand", "import delimited using \"raw.csv\"", 2, false, []string{"raw.csv"}},
+		{"unterminated quote", "append using \"x", 1, true, []string{"\"x"}},
+		{"unterminated compound quote", "append using `\"x", 1, true, []string{"`\"x"}},
+		{"unterminated quote after file", "append using a \"b", 1, true, []string{"a", "\"b"}},
+		{"no file", "use", 1, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stataFileArguments(tt.text, tt.skip, tt.all); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stataFileArguments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseStringForStataLineage(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []string
+	}{
+		{"use and save", "use raw/cohort, clear\nkeep if age > 18\nsave adults, replace\n", []string{"3-3 save raw/cohort.dta > adults.dta"}},
+		{"merge and append", "use a\nmerge 1:1 id using b, nogen\nappend using \"c d\" e\nsa out\n",
+			[]string{"4-4 save a.dta,b.dta,c d.dta,e.dta > out.dta"}},
+		{"save replace", "use cohort\ndrop x\nsave, replace\n", []string{"3-3 save cohort.dta > cohort.dta"}},
+		{"read only", "use cohort\ntab age\n", []string{"1-1 use cohort.dta > "}},
+		{"clear", "use a\nclear\nuse b\nsave c\n", []string{"1-1 use a.dta > ", "4-4 save b.dta > c.dta"}},
+		{"import and export", "import delimited using \"raw.csv\", clear\nexport excel using out.xlsx\n",
+			[]string{"2-2 export excel raw.csv > out.xlsx"}},
+		{"macros", "global data \"/proj/data\"\nuse \"$data/cohort\"\nsave `\"$data/final\"'\n",
+			[]string{"3-3 save /proj/data/cohort.dta > /proj/data/final.dta"}},
+		{"tempfile", "tempfile tmp\nuse a\nsave `tmp'\nuse b\nmerge 1:1 id using `tmp'\nsave c\n",
+			[]string{"3-3 save a.dta > tempfile tmp", "6-6 save b.dta,tempfile tmp > c.dta"}},
+		{"prefixed", "capture noisily use a\nqui save b\n", []string{"2-2 save a.dta > b.dta"}},
+		{"commented out", "* use a\n// save b\n", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			for _, step := range ParseStringForStataLineage(tt.contents) {
+				got = append(got, strconv.Itoa(step.LineNum)+"-"+strconv.Itoa(step.EndLineNum)+" "+step.Step+" "+
+					strings.Join(step.Inputs, ",")+" > "+strings.Join(step.Outputs, ","))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseStringForStataLineage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,350 @@
+/*
+ * Functions for separating live code from comments
+ */
+
+package parse
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// matches the |@keyword| that starts a Code Diary comment
+	commentKeywordRegex = regexp.MustCompile("^@[a-zA-Z\\.]+\\s*")
+
+	// matches the |/* */|, |**@keyword ;|, |* ;| and |//| comments that may trail code on a line, the |* ;| statement
+	// comment only where a statement has ended before it
+	sameLineCommentRegex = regexp.MustCompile("/\\*.*?\\*/|\\*\\*@[^;]*;|;\\s*\\*[^;]*;|\\s//[^/].*$")
+)
+
+// StripSASComments ... replace every SAS comment in a given string with spaces
+//
+// Newlines are retained so that offsets and line numbers into the stripped
+// string match those of the original. Handles |/* */| block comments along
+// with |* ;| and |%* ;| statement comments.
+func StripSASComments(contents string) string {
+
+	stripped := []byte(contents)
+	atStatementStart := true
+
+	for i := 0; i < len(stripped); i++ {
+		c := stripped[i]
+
+		switch {
+
+		// block comments, which may appear anywhere
+		case c == '/' && i+1 < len(stripped) && stripped[i+1] == '*':
+			end := strings.Index(contents[i+2:], "*/")
+			if end == -1 {
+				end = len(contents)
+			} else {
+				end += i + 4
+			}
+			blankRange(stripped, i, end)
+			i = end - 1
+
+		// statement comments, which must be at the start of a statement
+		case atStatementStart && (c == '*' || (c == '%' && i+1 < len(stripped) && stripped[i+1] == '*')):
+			end := strings.Index(contents[i:], ";")
+			if end == -1 {
+				end = len(contents)
+			} else {
+				end += i + 1
+			}
+			blankRange(stripped, i, end)
+			i = end - 1
+
+		// quoted strings are copied through as-is
+		case c == '\'' || c == '"':
+			end := strings.IndexByte(contents[i+1:], c)
+			if end == -1 {
+				i = len(stripped)
+			} else {
+				i += end + 1
+			}
+			atStatementStart = false
+
+		case c == ';':
+			atStatementStart = true
+
+		case isSpace(c):
+			continue
+
+		default:
+			atStatementStart = false
+		}
+	}
+
+	return string(stripped)
+}
+
+// StripStataComments ... replace every Stata comment in a given string with spaces
+//
+// Newlines are retained so that offsets and line numbers into the stripped
+// string match those of the original. Handles nested |/* */| block comments,
+// |//| and |///| comments, along with lines starting with a |*|.
+func StripStataComments(contents string) string {
+
+	stripped := []byte(contents)
+	atLineStart := true
+
+	for i := 0; i < len(stripped); i++ {
+		c := stripped[i]
+
+		switch {
+
+		// block comments, which may be nested
+		case c == '/' && i+1 < len(stripped) && stripped[i+1] == '*':
+			depth := 0
+			end := i
+			for ; end < len(stripped); end++ {
+				if strings.HasPrefix(contents[end:], "/*") {
+					depth++
+					end++
+				} else if strings.HasPrefix(contents[end:], "*/") {
+					depth--
+					end++
+					if depth == 0 {
+						end++
+						break
+					}
+				}
+			}
+			blankRange(stripped, i, end)
+			i = end - 1
+
+		// line comments, either |//| preceded by whitespace or a line starting with |*|
+		case (c == '/' && i+1 < len(stripped) && stripped[i+1] == '/' && (i == 0 || isSpace(stripped[i-1]))) ||
+			(c == '*' && atLineStart):
+			end := strings.IndexByte(contents[i:], '\n')
+			if end == -1 {
+				end = len(contents)
+			} else {
+				end += i
+			}
+			blankRange(stripped, i, end)
+			i = end - 1
+
+		// quoted strings are copied through as-is
+		case c == '"':
+			end := strings.IndexAny(contents[i+1:], "\"\n")
+			if end == -1 {
+				i = len(stripped)
+			} else {
+				i += end + 1
+			}
+			atLineStart = i < len(stripped) && stripped[i] == '\n'
+
+		case c == '\n':
+			atLineStart = true
+
+		case isSpace(c):
+			continue
+
+		default:
+			atLineStart = false
+		}
+	}
+
+	return string(stripped)
+}
+
+// isSpace ... whether a given character is whitespace
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == '\v'
+}
+
+// blankRange ... overwrite the bytes in [start, end) with spaces, keeping newlines
+func blankRange(b []byte, start, end int) {
+	if end > len(b) {
+		end = len(b)
+	}
+	for i := start; i < end; i++ {
+		if b[i] != '\n' {
+			b[i] = ' '
+		}
+	}
+}
+
+// LineNumberAt ... obtain the line number that a given byte offset appears on
+func LineNumberAt(contents string, offset int) int {
+	if offset > len(contents) {
+		offset = len(contents)
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return strings.Count(contents[:offset], "\n") + 1
+}
+
+// PrecedingComment ... obtain the text of the comment lines directly above a given line number
+//
+// Comment delimiters, along with any leading |@keyword|, are trimmed away so
+// that only the descriptive text remains.
+func PrecedingComment(contents string, lineNum int) string {
+
+	lines := strings.Split(contents, "\n")
+	collected := make([]string, 0)
+	inBlock := false
+
+	for i := lineNum - 2; i >= 0 && i < len(lines); i-- {
+		line := strings.TrimSpace(lines[i])
+
+		switch {
+		case inBlock:
+			if strings.HasPrefix(line, "/*") {
+				inBlock = false
+			}
+		case strings.HasSuffix(line, "*/") && strings.HasPrefix(line, "/*"):
+		case strings.HasSuffix(line, "*/") && !strings.Contains(line, "/*"):
+			inBlock = true
+		case strings.HasPrefix(line, "*") || strings.HasPrefix(line, "//"):
+		default:
+			i = -1
+			continue
+		}
+
+		collected = append([]string{line}, collected...)
+	}
+
+	text := ""
+	for _, line := range collected {
+		line = strings.TrimLeft(line, "/*~!")
+		line = strings.TrimRight(line, "/*~;")
+		line = strings.TrimSpace(line)
+		line = strings.TrimSpace(commentKeywordRegex.ReplaceAllString(line, ""))
+		if line != "" {
+			text = strings.TrimSpace(text + " " + line)
+		}
+	}
+
+	return text
+}
+
+// Statement object definition
+type Statement struct {
+
+	// byte offset of the statement within the string it was split from
+	Offset int
+
+	// text of the statement, excluding the terminating semicolon
+	Text string
+}
+
+// SplitSASStatements ... split a string of SAS code, with comments already stripped, into its statements
+func SplitSASStatements(code string) []Statement {
+
+	statements := make([]Statement, 0)
+	start := 0
+
+	for i := 0; i < len(code); i++ {
+		switch code[i] {
+		case '\'', '"':
+			if end := strings.IndexByte(code[i+1:], code[i]); end != -1 {
+				i += end + 1
+			}
+		case ';':
+			statements = appendStatement(statements, code, start, i)
+			start = i + 1
+		}
+	}
+
+	return appendStatement(statements, code, start, len(code))
+}
+
+// appendStatement ... append the statement in [start, end) of the code, skipping leading whitespace
+func appendStatement(statements []Statement, code string, start int, end int) []Statement {
+	for start < end && isSpace(code[start]) {
+		start++
+	}
+	if start == end {
+		return statements
+	}
+	return append(statements, Statement{start, code[start:end]})
+}
+
+// SplitStataCommands ... split a string of Stata code into its commands, with comments stripped
+//
+// Lines continued via |///| are joined, and commands are split by semicolons
+// rather than newlines while |#delimit ;| is in effect.
+func SplitStataCommands(contents string) []Statement {
+
+	commands := make([]Statement, 0)
+	code := StripStataComments(contents)
+	originalLines := strings.Split(contents, "\n")
+	semicolons := false
+
+	pending := ""
+	pendingOffset := -1
+	flush := func() {
+		if strings.TrimSpace(pending) != "" {
+			commands = append(commands, Statement{pendingOffset, strings.Join(strings.Fields(pending), " ")})
+		}
+		pending = ""
+		pendingOffset = -1
+	}
+
+	offset := 0
+	for i, line := range strings.Split(code, "\n") {
+
+		lineOffset := offset
+		offset += len(line) + 1
+		trimmed := strings.TrimSpace(line)
+
+		// handle the |#delimit ;| and |#delimit cr| directives
+		if fields := strings.Fields(trimmed); len(fields) > 1 && isAbbreviationOf(fields[0], "#delimit", 2) {
+			flush()
+			semicolons = fields[1] == ";"
+			continue
+		}
+
+		if trimmed != "" && pendingOffset == -1 {
+			pendingOffset = lineOffset + strings.Index(line, trimmed)
+		}
+
+		if !semicolons {
+			pending += " " + line
+			if !strings.Contains(originalLines[i], "///") {
+				flush()
+			}
+			continue
+		}
+
+		for {
+			end := strings.IndexByte(line, ';')
+			if end == -1 {
+				pending += " " + line
+				break
+			}
+			pending += " " + line[:end]
+			flush()
+			lineOffset += end + 1
+			line = line[end+1:]
+			if trimmed := strings.TrimSpace(line); trimmed != "" {
+				pendingOffset = lineOffset + strings.Index(line, trimmed)
+			}
+		}
+	}
+	flush()
+
+	return commands
+}
+
+// AdjacentComment ... obtain the text of a comment on the same line as, or directly above, a given line number
+func AdjacentComment(contents string, lineNum int) string {
+
+	lines := strings.Split(contents, "\n")
+	if lineNum >= 1 && lineNum <= len(lines) {
+		line := lines[lineNum-1]
+		for _, match := range sameLineCommentRegex.FindAllString(line, -1) {
+			text := strings.TrimSpace(strings.Trim(match, "/*; \t"))
+			text = strings.TrimSpace(commentKeywordRegex.ReplaceAllString(text, ""))
+			if text != "" {
+				return text
+			}
+		}
+	}
+
+	return PrecedingComment(contents, lineNum)
+}
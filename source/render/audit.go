@@ -0,0 +1,62 @@
+/*
+ * Functions for rendering the audit report of external commands and hard-coded paths
+ */
+
+package render
+
+import (
+	"io"
+	"path/filepath"
+	"strconv"
+
+	"github.com/rbisewski/gommentary/source/fileutils"
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// AuditReport ... generate the markdown report of the external commands and hard-coded paths of a project
+func AuditReport(project model.Project) string {
+
+	markdownContents := "% Audit of external commands and hard-coded paths\n"
+
+	markdownContents += "\n# External commands\n\n"
+	commands := ""
+	for _, incl := range project.Includes {
+		if incl.Kind != model.DependencyCommand {
+			continue
+		}
+		commands += "| " + incl.Filename + " | " + strconv.Itoa(incl.LineNum) + " | " + incl.Statement + " | " +
+			escapeTableCell(incl.Path) + " |\n"
+	}
+	if commands == "" {
+		markdownContents += "No external commands were found.\n"
+	} else {
+		markdownContents += "| File | Line | Statement | Command |\n|---|---|---|---|\n" + commands
+	}
+
+	markdownContents += "\n# Hard-coded paths\n\n"
+	if len(project.Paths) < 1 {
+		markdownContents += "No absolute paths were found.\n"
+	} else {
+		markdownContents += "| File | Line | Path |\n|---|---|---|\n"
+		for _, path := range project.Paths {
+			markdownContents += "| " + path.Filename + " | " + strconv.Itoa(path.LineNum) + " | " +
+				escapeTableCell(path.Name) + " |\n"
+		}
+	}
+
+	return markdownContents
+}
+
+// Audit ... renderer of the audit report of a project
+type Audit struct{}
+
+// Render ... write the audit report of a project out to w
+func (a Audit) Render(w io.Writer, project model.Project) error {
+	_, err := io.WriteString(w, AuditReport(project))
+	return err
+}
+
+// WriteAudit ... write the audit report of a project out to the docs directory
+func WriteAudit(docsDir string, filename string, project model.Project) error {
+	return fileutils.WriteToFile(filepath.Join(docsDir, filename), AuditReport(project), true)
+}
@@ -0,0 +1,60 @@
+/*
+ * Functions for rendering the macro call graph and its documentation section
+ */
+
+package render
+
+import (
+	"strconv"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// macroNode ... obtain the name of the call graph node for a given caller
+func macroNode(call model.MacroCall) string {
+	if call.Caller == "" {
+		return call.Filename
+	}
+	return "%" + call.Caller
+}
+
+// MacroCallGraph ... assemble the call graph from the given macro invocations
+func MacroCallGraph(calls []model.MacroCall) Graph {
+	g := Graph{Name: "macro-calls"}
+	for _, call := range calls {
+		g.AddEdge(macroNode(call), "%"+call.Name, "")
+	}
+	return g
+}
+
+// MacroCallSections ... generate the markdown sections describing the macro call graph
+func MacroCallSections(macros []model.Macro, calls []model.MacroCall, graphFormats []string) string {
+
+	if len(calls) < 1 {
+		return ""
+	}
+
+	markdownContents := ""
+
+	for _, format := range graphFormats {
+		if format == GraphFormatText {
+			markdownContents += "\n# Macro call graph\n\n" + GraphToText(MacroCallGraph(calls))
+		}
+	}
+
+	if unused := model.UnusedMacros(macros, calls); len(unused) > 0 {
+		markdownContents += "\n# Macros defined but never called\n\n"
+		for _, macro := range unused {
+			markdownContents += "* %" + macro.Name + ": " + macro.Filename + ":" + strconv.Itoa(macro.LineNum) + "\n"
+		}
+	}
+
+	if undefined := model.UndefinedMacroCalls(macros, calls); len(undefined) > 0 {
+		markdownContents += "\n# Calls to undefined macros\n\n"
+		for _, call := range undefined {
+			markdownContents += "* %" + call.Name + ": " + call.Filename + ":" + strconv.Itoa(call.LineNum) + "\n"
+		}
+	}
+
+	return markdownContents
+}
@@ -0,0 +1,78 @@
+/*
+ * Functions for rendering the data dictionary documented via the @var comments
+ */
+
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// DataDictionarySections ... generate the markdown data dictionary, with a table for each dataset
+func DataDictionarySections(variables []model.Variable) string {
+
+	if len(variables) < 1 {
+		return ""
+	}
+
+	// group the variables by dataset, in order of first appearance
+	datasets := make([]string, 0)
+	for _, variable := range variables {
+		datasets = model.AppendUnique(datasets, variable.Dataset)
+	}
+	for _, variable := range variables {
+		if variable.Dataset == "" {
+			datasets = append(datasets, "")
+			break
+		}
+	}
+
+	markdownContents := "\n# Data dictionary\n"
+
+	for _, dataset := range datasets {
+
+		heading := dataset
+		if heading == "" {
+			heading = "Other variables"
+		}
+		markdownContents += "\n## " + heading + "\n\n"
+		markdownContents += "| Variable | Type | Label | Codes | Description | Documented in |\n"
+		markdownContents += "|---|---|---|---|---|---|\n"
+
+		for _, variable := range variables {
+			if variable.Dataset != dataset {
+				continue
+			}
+
+			// any remaining attributes are appended to the description
+			description := variable.Description
+			for _, attribute := range variable.Attributes {
+				description = strings.TrimSpace(description + " (" + attribute.Name + ": " + attribute.Value + ")")
+			}
+
+			sources := make([]string, 0, 2)
+			if variable.Filename != "" {
+				sources = append(sources, variable.Filename+":"+strconv.Itoa(variable.LineNum))
+			}
+			if variable.Dictionary != "" {
+				sources = append(sources, variable.Dictionary)
+			}
+
+			markdownContents += "| " + variable.Name + " | " + variable.Type + " | " + escapeTableCell(variable.Label) +
+				" | " + escapeTableCell(variable.Codes) + " | " + escapeTableCell(description) + " | " +
+				strings.Join(sources, ", ") + " |\n"
+		}
+	}
+
+	if discrepancies := model.DictionaryDiscrepancies(variables); len(discrepancies) > 0 {
+		markdownContents += "\n# Data dictionary discrepancies\n\n"
+		for _, discrepancy := range discrepancies {
+			markdownContents += "* " + discrepancy + "\n"
+		}
+	}
+
+	return markdownContents
+}
@@ -0,0 +1,92 @@
+/*
+ * Functions for documenting the datasets of the data directory and where they are used
+ */
+
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// datasetUsage ... describe the steps of the project that read or write a given dataset
+func datasetUsage(dataset model.Dataset, steps []model.DatasetStep, libraries map[string]string) ([]string, []string) {
+
+	node := strings.ToLower(filepath.Base(dataset.Filename))
+	readBy := make([]string, 0)
+	writtenBy := make([]string, 0)
+
+	for _, step := range steps {
+		location := step.Filename + ":" + strconv.Itoa(step.LineNum) + " (" + step.Step + ")"
+		for _, input := range step.Inputs {
+			if model.DatasetNode(input, step.Filename, libraries) == node {
+				readBy = model.AppendUnique(readBy, location)
+			}
+		}
+		for _, output := range step.Outputs {
+			if model.DatasetNode(output, step.Filename, libraries) == node {
+				writtenBy = model.AppendUnique(writtenBy, location)
+			}
+		}
+	}
+
+	return readBy, writtenBy
+}
+
+// DatasetSections ... generate the markdown reference section of each dataset, along with the steps that use it
+func DatasetSections(datasets []model.Dataset, steps []model.DatasetStep, libraries map[string]string) string {
+
+	if len(datasets) < 1 {
+		return ""
+	}
+
+	markdownContents := "\n# Datasets\n"
+
+	for _, dataset := range datasets {
+
+		markdownContents += "\n## " + filepath.Base(dataset.Filename) + "\n\n"
+		if dataset.Label != "" {
+			markdownContents += dataset.Label + "\n\n"
+		}
+		markdownContents += fmt.Sprintf("* File: %s\n* Format: %s\n* Observations: %d\n* Variables: %d\n",
+			dataset.Filename, dataset.Format, dataset.Observations, len(dataset.Variables))
+		if !dataset.Created.IsZero() {
+			markdownContents += "* Created: " + dataset.Created.Format("2006-01-02 15:04") + "\n"
+		}
+
+		readBy, writtenBy := datasetUsage(dataset, steps, libraries)
+		if len(writtenBy) > 0 {
+			markdownContents += "* Written by: " + strings.Join(writtenBy, ", ") + "\n"
+		}
+		if len(readBy) > 0 {
+			markdownContents += "* Read by: " + strings.Join(readBy, ", ") + "\n"
+		}
+
+		if len(dataset.Variables) > 0 {
+			markdownContents += "\n| Variable | Type | Length | Format | Label | Value labels |\n"
+			markdownContents += "|---|---|---|---|---|---|\n"
+			for _, variable := range dataset.Variables {
+				length := ""
+				if variable.Length > 0 {
+					length = strconv.Itoa(variable.Length)
+				}
+				markdownContents += "| " + variable.Name + " | " + variable.Type + " | " + length + " | " + variable.Format +
+					" | " + escapeTableCell(variable.Label) + " | " + variable.ValueLabel + " |\n"
+			}
+		}
+
+		for _, set := range dataset.ValueLabels {
+			labels := make([]string, 0, len(set.Labels))
+			for _, label := range set.Labels {
+				labels = append(labels, label.Value+" = "+label.Label)
+			}
+			markdownContents += "\n### Value labels " + set.Name + "\n\n" + strings.Join(labels, ", ") + "\n"
+		}
+	}
+
+	return markdownContents
+}
@@ -0,0 +1,94 @@
+/*
+ * Functions for rendering the dependency graph and its documentation sections
+ */
+
+package render
+
+import (
+	"strconv"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// headings of the documentation sections for each kind of dependency
+var dependencyHeadings = []struct {
+	Kind    string
+	Heading string
+}{
+	{model.DependencyScript, "Scripts used for project"},
+	{model.DependencyMacro, "Macro libraries used for project"},
+	{model.DependencyAutocall, "Autocall paths used for project"},
+	{model.DependencyReference, "File and library references"},
+	{model.DependencyPackage, "Packages used for project"},
+	{model.DependencyCommand, "External commands"},
+}
+
+// DependencyGraph ... assemble the graph of files and the scripts, macros and autocall paths they depend on
+func DependencyGraph(includes []model.Dependency) Graph {
+	g := Graph{Name: "dependencies"}
+	for _, incl := range includes {
+		if incl.Filename == "" || incl.Path == "" {
+			continue
+		}
+		if incl.Kind == model.DependencyReference || incl.Kind == model.DependencyCommand {
+			continue
+		}
+		g.AddEdge(incl.Filename, incl.Path, incl.Statement)
+	}
+	return g
+}
+
+// DependencySections ... generate the markdown sections listing each kind of dependency and the dependency graph
+func DependencySections(includes []model.Dependency, graphFormats []string) string {
+
+	markdownContents := ""
+
+	for _, section := range dependencyHeadings {
+
+		listed := make(map[string]int)
+		entries := ""
+
+		for _, incl := range includes {
+
+			if incl.Kind != section.Kind || incl.Path == "" {
+				continue
+			}
+
+			entry := ""
+			switch incl.Kind {
+			case model.DependencyReference:
+				entry = "* " + incl.Statement + " " + incl.Name + ": " + incl.Path + "\n"
+			case model.DependencyCommand:
+				entry = "* " + incl.Filename + ":" + strconv.Itoa(incl.LineNum) + " " + incl.Statement + ": " +
+					incl.Path + "\n"
+			default:
+				entry = "* " + incl.Path + "\n"
+			}
+
+			// skip already appended entries
+			if listed[entry] == 1 {
+				continue
+			}
+			listed[entry] = 1
+
+			entries += entry
+		}
+
+		if entries != "" {
+			markdownContents += "\n# " + section.Heading + "\n\n" + entries
+		}
+	}
+
+	g := DependencyGraph(includes)
+	if len(g.Edges) < 1 {
+		return markdownContents
+	}
+
+	for _, format := range graphFormats {
+		if format == GraphFormatText {
+			markdownContents += "\n# Dependency graph\n\n" + GraphToText(g)
+		}
+	}
+
+	return markdownContents
+}
@@ -0,0 +1,60 @@
+/*
+ * Functions for rendering the formats defined via PROC FORMAT
+ */
+
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// escapeTableCell ... escape the characters of a string that would break a markdown table
+func escapeTableCell(str string) string {
+	str = strings.Replace(str, "|", "\\|", -1)
+	return strings.Replace(str, "\n", " ", -1)
+}
+
+// FormatSections ... generate the markdown reference table of the formats defined in the project
+func FormatSections(formats []model.Format) string {
+
+	if len(formats) < 1 {
+		return ""
+	}
+
+	markdownContents := "\n# Format definitions\n\n"
+	markdownContents += "| Format | Type | Range | Label | Defined in | Comment |\n"
+	markdownContents += "|---|---|---|---|---|---|\n"
+
+	for _, format := range formats {
+
+		name := format.Name
+		kind := format.Type
+		if format.Character {
+			name = "$" + name
+			kind += ", character"
+		} else {
+			kind += ", numeric"
+		}
+		location := format.Filename + ":" + strconv.Itoa(format.LineNum)
+		comment := escapeTableCell(format.Comment)
+
+		// a format without any ranges still deserves a row
+		ranges := format.Ranges
+		if len(ranges) < 1 {
+			ranges = []model.FormatRange{{}}
+		}
+
+		for _, formatRange := range ranges {
+			markdownContents += "| " + name + " | " + kind + " | " + escapeTableCell(formatRange.Range) + " | " +
+				escapeTableCell(formatRange.Label) + " | " + location + " | " + comment + " |\n"
+
+			// only the first row of each format repeats its details
+			name, kind, location, comment = "", "", "", ""
+		}
+	}
+
+	return markdownContents
+}
@@ -0,0 +1,143 @@
+/*
+ * Functions for rendering graphs as text, DOT and Mermaid
+ */
+
+package render
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/fileutils"
+)
+
+// Formats in which graphs may be rendered
+const (
+	GraphFormatText    = "text"
+	GraphFormatDOT     = "dot"
+	GraphFormatMermaid = "mermaid"
+)
+
+// GraphEdge object definition
+type GraphEdge struct {
+
+	// node the edge starts from
+	From string
+
+	// node the edge points to
+	To string
+
+	// optional text describing the edge
+	Label string
+}
+
+// Graph object definition
+type Graph struct {
+
+	// name of the graph, also used as the filename for DOT and Mermaid output
+	Name string
+
+	// edges of the graph, in the order they were added
+	Edges []GraphEdge
+}
+
+// AddEdge ... append an edge to the graph, skipping duplicates
+func (g *Graph) AddEdge(from string, to string, label string) {
+	for _, edge := range g.Edges {
+		if edge.From == from && edge.To == to && edge.Label == label {
+			return
+		}
+	}
+	g.Edges = append(g.Edges, GraphEdge{from, to, label})
+}
+
+// GraphToText ... render a graph as a markdown list of edges
+func GraphToText(g Graph) string {
+	text := ""
+	for _, edge := range g.Edges {
+		text += "* " + edge.From + " -> " + edge.To
+		if edge.Label != "" {
+			text += " (" + edge.Label + ")"
+		}
+		text += "\n"
+	}
+	return text
+}
+
+// GraphToDOT ... render a graph in the Graphviz DOT language
+func GraphToDOT(g Graph) string {
+	text := "digraph " + quoteGraphID(g.Name) + " {\n"
+	for _, edge := range g.Edges {
+		text += "\t" + quoteGraphID(edge.From) + " -> " + quoteGraphID(edge.To)
+		if edge.Label != "" {
+			text += " [label=" + quoteGraphID(edge.Label) + "]"
+		}
+		text += ";\n"
+	}
+	return text + "}\n"
+}
+
+// GraphToMermaid ... render a graph as a Mermaid flowchart
+func GraphToMermaid(g Graph) string {
+
+	// mermaid node ids must be simple, so number each node and use the
+	// actual name as its label
+	ids := make(map[string]string)
+	nodeID := func(name string) string {
+		id, ok := ids[name]
+		if !ok {
+			id = fmt.Sprintf("n%d", len(ids)+1)
+			ids[name] = id
+			return id + "[" + quoteGraphID(name) + "]"
+		}
+		return id
+	}
+
+	text := "flowchart LR\n"
+	for _, edge := range g.Edges {
+		from := nodeID(edge.From)
+		to := nodeID(edge.To)
+		if edge.Label != "" {
+			text += "\t" + from + " -->|" + quoteGraphID(edge.Label) + "| " + to + "\n"
+		} else {
+			text += "\t" + from + " --> " + to + "\n"
+		}
+	}
+	return text
+}
+
+// quoteGraphID ... wrap a node name in double quotes, escaping any inside of it
+func quoteGraphID(str string) string {
+	return "\"" + strings.Replace(str, "\"", "\\\"", -1) + "\""
+}
+
+// WriteGraph ... write out a graph into the docs directory in each of the given formats, other than text
+func WriteGraph(docsDir string, g Graph, formats []string) error {
+
+	for _, format := range formats {
+
+		contents := ""
+		extension := ""
+
+		switch format {
+		case GraphFormatText:
+			continue
+		case GraphFormatDOT:
+			contents = GraphToDOT(g)
+			extension = ".dot"
+		case GraphFormatMermaid:
+			contents = GraphToMermaid(g)
+			extension = ".mmd"
+		default:
+			return fmt.Errorf("Unknown graph format: %s", format)
+		}
+
+		err := fileutils.WriteToFile(filepath.Join(docsDir, g.Name+extension), contents, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+ * Functions for rendering the dataset lineage graphs and their documentation section
+ */
+
+package render
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// ProjectLineageGraph ... assemble the lineage graph of the whole project, joining the datasets shared by files
+func ProjectLineageGraph(steps []model.DatasetStep, libraries map[string]string) Graph {
+	g := Graph{Name: "lineage"}
+	for _, step := range steps {
+		for _, input := range step.Inputs {
+			for _, output := range step.Outputs {
+				g.AddEdge(model.DatasetNode(input, step.Filename, libraries), model.DatasetNode(output, step.Filename, libraries),
+					filepath.Base(step.Filename)+": "+step.Step)
+			}
+		}
+	}
+	return g
+}
+
+// LineageGraph ... assemble the graph of datasets read and written by the given steps
+func LineageGraph(name string, steps []model.DatasetStep) Graph {
+	g := Graph{Name: name}
+	for _, step := range steps {
+		for _, input := range step.Inputs {
+			for _, output := range step.Outputs {
+				g.AddEdge(input, output, step.Step)
+			}
+		}
+	}
+	return g
+}
+
+// lineageGraphName ... obtain the name of the lineage graph of a given file, including its extension
+//
+// The graph of a part of a file, such as a notebook cell, is named after
+// both, with anything but letters and digits in the part made an underscore.
+func lineageGraphName(filename string) string {
+	filename, part := model.SplitPart(filename)
+	name := "lineage-" + filepath.Base(filename)
+	if part != "" {
+		name += "-" + strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return '_'
+		}, part)
+	}
+	return name
+}
+
+// LineageGraphs ... assemble the lineage graph of each file, in the order the files were read
+//
+// Files of the same name in different directories have their graphs numbered
+// from the second one on, e.g. |lineage-analysis.sas-2|.
+func LineageGraphs(steps []model.DatasetStep) []Graph {
+	graphs := make([]Graph, 0)
+	names := make(map[string]int)
+	for _, filename := range lineageFiles(steps) {
+		fileSteps := make([]model.DatasetStep, 0)
+		for _, step := range steps {
+			if step.Filename == filename {
+				fileSteps = append(fileSteps, step)
+			}
+		}
+		name := lineageGraphName(filename)
+		names[name]++
+		if names[name] > 1 {
+			name += "-" + strconv.Itoa(names[name])
+		}
+		graphs = append(graphs, LineageGraph(name, fileSteps))
+	}
+	return graphs
+}
+
+// lineageFiles ... obtain the files containing the given steps, in order
+func lineageFiles(steps []model.DatasetStep) []string {
+	files := make([]string, 0)
+	for _, step := range steps {
+		files = model.AppendUnique(files, step.Filename)
+	}
+	return files
+}
+
+// LineageSections ... generate the markdown lineage table and graph of each file
+func LineageSections(steps []model.DatasetStep, libraries map[string]string, graphFormats []string) string {
+
+	if len(steps) < 1 {
+		return ""
+	}
+
+	markdownContents := "\n# Dataset lineage\n"
+	graphs := LineageGraphs(steps)
+
+	for i, filename := range lineageFiles(steps) {
+
+		markdownContents += "\n## " + filename + "\n\n"
+		markdownContents += "| Line | Step | Reads | Writes |\n"
+		markdownContents += "|---|---|---|---|\n"
+
+		for _, step := range steps {
+			if step.Filename != filename {
+				continue
+			}
+			inputs := make([]string, 0, len(step.Inputs))
+			for _, input := range step.Inputs {
+				inputs = append(inputs, model.DescribeDataset(input, libraries))
+			}
+			outputs := make([]string, 0, len(step.Outputs))
+			for _, output := range step.Outputs {
+				outputs = append(outputs, model.DescribeDataset(output, libraries))
+			}
+			markdownContents += "| " + strconv.Itoa(step.LineNum) + " | " + step.Step + " | " +
+				strings.Join(inputs, ", ") + " | " + strings.Join(outputs, ", ") + " |\n"
+		}
+
+		if len(graphs[i].Edges) < 1 {
+			continue
+		}
+		for _, format := range graphFormats {
+			if format == GraphFormatText {
+				markdownContents += "\n" + GraphToText(graphs[i])
+			}
+		}
+	}
+
+	// the project graph only differs from that of the file if there are many
+	if len(graphs) < 2 {
+		return markdownContents
+	}
+	g := ProjectLineageGraph(steps, libraries)
+	for _, format := range graphFormats {
+		if format == GraphFormatText && len(g.Edges) > 0 {
+			markdownContents += "\n## Whole project\n\n" + GraphToText(g)
+		}
+	}
+
+	return markdownContents
+}
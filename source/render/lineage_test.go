@@ -0,0 +1,25 @@
+package render
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestLineageGraphs(t *testing.T) {
+	steps := []model.DatasetStep{
+		{Filename: "code/analysis.sas", Step: "data", Inputs: []string{"a"}, Outputs: []string{"b"}},
+		{Filename: "code/analysis.do", Step: "save", Inputs: []string{"b"}, Outputs: []string{"c"}},
+		{Filename: "other/analysis.sas", Step: "data", Inputs: []string{"c"}, Outputs: []string{"d"}},
+		{Filename: "flow.egp › Load visits", Step: "data", Inputs: []string{"d"}, Outputs: []string{"e"}},
+	}
+	got := make([]string, 0)
+	for _, g := range LineageGraphs(steps) {
+		got = append(got, g.Name)
+	}
+	want := []string{"lineage-analysis.sas", "lineage-analysis.do", "lineage-analysis.sas-2", "lineage-flow.egp-Load_visits"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LineageGraphs() names = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+ * Functions for rendering the documentation of SAS macros, along with the functions of other languages
+ */
+
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// macroSigil ... obtain the |%| preceding the name of a SAS macro, which the functions of other languages lack
+func macroSigil(macro model.Macro) string {
+	if macro.Language == "" || macro.Language == model.LanguageSAS {
+		return "%"
+	}
+	return ""
+}
+
+// MacroSignature ... assemble the |%name(param, param=default)| form of a macro, or |name(...)| of a function
+func MacroSignature(macro model.Macro) string {
+	params := make([]string, 0, len(macro.Params))
+	for _, param := range macro.Params {
+		if param.Keyword {
+			params = append(params, param.Name+"="+param.Default)
+		} else {
+			params = append(params, param.Name)
+		}
+	}
+	return macroSigil(macro) + macro.Name + "(" + strings.Join(params, ", ") + ")"
+}
+
+// MacroSections ... generate the markdown sections describing the macros and their documentation issues
+func MacroSections(macros []model.Macro) string {
+
+	if len(macros) < 1 {
+		return ""
+	}
+
+	markdownContents := "\n# Macros defined in project\n\n"
+	for _, macro := range macros {
+		markdownContents += fmt.Sprintf("* %s: %s:%d", MacroSignature(macro), macro.Filename, macro.LineNum)
+		if macro.Summary != "" {
+			markdownContents += " " + macro.Summary
+		}
+		markdownContents += "\n"
+	}
+
+	issues := model.CheckMacroDocumentation(macros)
+	if len(issues) < 1 {
+		return markdownContents
+	}
+
+	sigils := make(map[string]string)
+	for _, macro := range macros {
+		sigils[macro.Filename+":"+macro.Name] = macroSigil(macro)
+	}
+
+	markdownContents += "\n# Macro documentation issues\n\n"
+	for _, issue := range issues {
+		markdownContents += fmt.Sprintf("* %s:%d %s%s: parameter \"%s\" %s\n",
+			issue.Filename, issue.LineNum, sigils[issue.Filename+":"+issue.Macro], issue.Macro, issue.Param, issue.Problem)
+	}
+
+	return markdownContents
+}
@@ -0,0 +1,71 @@
+/*
+ * Functions for rendering the configuration variables assigned via %let and %global
+ */
+
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// ConfigurationSections ... generate the markdown table of the global macro variables and where they are used
+func ConfigurationSections(variables []model.MacroVariable, references []model.Reference) string {
+
+	variables = model.GlobalMacroVariables(variables)
+	if len(variables) < 1 {
+		return ""
+	}
+
+	// list the assignments of each variable together, in order of first appearance
+	names := make([]string, 0)
+	assignments := make(map[string][]model.MacroVariable)
+	for _, variable := range variables {
+		name := strings.ToLower(variable.Name)
+		if _, ok := assignments[name]; !ok {
+			names = append(names, name)
+		}
+		assignments[name] = append(assignments[name], variable)
+	}
+
+	usages := make(map[string][]string)
+	for _, reference := range references {
+		name := strings.ToLower(reference.Name)
+		usages[name] = model.AppendUnique(usages[name], reference.Filename+":"+strconv.Itoa(reference.LineNum))
+	}
+
+	markdownContents := "\n# Configuration variables\n\n"
+	markdownContents += "| Variable | Value | Assigned in | Comment | Referenced in |\n"
+	markdownContents += "|---|---|---|---|---|\n"
+
+	for _, name := range names {
+
+		label := "&" + assignments[name][0].Name
+		referenced := strings.Join(usages[name], ", ")
+		if referenced == "" {
+			referenced = "never"
+		}
+
+		for _, variable := range assignments[name] {
+
+			value := "declared via %global"
+			if variable.Value != "" || variable.Statement == "%let" {
+				value = "`" + escapeTableCell(variable.Value) + "`"
+			}
+			location := variable.Filename + ":" + strconv.Itoa(variable.LineNum)
+			if variable.Macro != "" {
+				location += " (%" + variable.Macro + ")"
+			}
+
+			markdownContents += "| " + label + " | " + value + " | " + location + " | " +
+				escapeTableCell(variable.Comment) + " | " + referenced + " |\n"
+
+			// only the first row of each variable repeats its name and references
+			label, referenced = "", ""
+		}
+	}
+
+	return markdownContents
+}
@@ -0,0 +1,233 @@
+/*
+ * Functions for rendering the documentation of a project as markdown
+ */
+
+package render
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/fileutils"
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// Renderer ... renderer of the documentation of a project in some format
+type Renderer interface {
+
+	// Render ... write the documentation of a project out to w
+	Render(w io.Writer, project model.Project) error
+}
+
+// Markdown ... renderer of the main documentation of a project, for both coders and everyone else
+type Markdown struct {
+
+	// formats of the graphs to render; only text graphs are part of the documentation itself
+	GraphFormats []string
+
+	// whether comments that cannot be rendered are skipped, rather than stopping the render
+	KeepGoing bool
+}
+
+// Render ... generate the markdown documentation of the comments, macros, datasets and dependencies of a project
+//
+// When keeping going, the documentation is written without the comments that
+// could not be rendered, whose errors are then returned as a
+// |model.ErrorList|.
+func (m Markdown) Render(w io.Writer, project model.Project) error {
+
+	graphFormats := m.GraphFormats
+
+	comments := project.Comments
+	if len(comments) < 1 {
+		return fmt.Errorf("No comments were present in the files. Exiting...")
+	}
+
+	markdownContents := ""
+	failures := make(model.ErrorList, 0)
+
+	//
+	// Title comments
+	//
+	for _, cmt := range comments {
+
+		// skip normal comments
+		if cmt.Keyword == "" || strings.Index(cmt.Text, ":") != 0 {
+			continue
+		}
+
+		// assemble title / author / organization / version information
+		attributes, rest := model.ParseAttributes(cmt.Text)
+		if len(attributes) < 1 || attributes[0].Value == "" {
+			err := model.NewError(cmt.Filename, cmt.LineNum, fmt.Errorf("Improperly formatted title comment."))
+			if !m.KeepGoing {
+				return err
+			}
+			failures.Add(cmt.Filename, cmt.LineNum, err)
+			continue
+		}
+		text := strings.TrimSpace(attributes[0].Value + " " + rest)
+		if attributes[0].Name == "version" {
+			markdownContents += "% Version " + strings.Title(text) + "\n"
+		} else {
+			markdownContents += "% " + strings.Title(text) + "\n"
+		}
+	}
+
+	//
+	// Code files used in the project
+	//
+	order := 1
+	keywordsMap := make(map[string]int)
+	filesMap := make(map[int]string)
+	markdownContents += "\n# Code files used for project\n\n"
+	for _, cmt := range comments {
+
+		// skip title comments
+		if cmt.Keyword != "" && strings.Index(cmt.Text, ":") == 0 {
+			continue
+		}
+
+		filesMap[cmt.Index] = cmt.Filename
+
+		// add keyword subtitles
+		trimmedKeyword := strings.TrimSpace(cmt.Keyword)
+		trimmedKeyword = strings.Trim(trimmedKeyword, "@")
+		if trimmedKeyword != "" && trimmedKeyword != "var" && keywordsMap[trimmedKeyword] == 0 {
+			keywordsMap[trimmedKeyword] = order
+			order++
+		}
+	}
+	for i := 1; i <= len(filesMap); i++ {
+		indexAsString := strconv.FormatInt(int64(i), 10)
+		markdownContents += "* " + indexAsString + ": " + filesMap[i] + "\n"
+	}
+
+	//
+	// Scripts, macros, references and commands used for the project
+	//
+	markdownContents += DependencySections(project.Includes, graphFormats)
+
+	//
+	// Macros defined in the project, along with any documentation issues
+	//
+	markdownContents += MacroSections(project.Macros)
+	markdownContents += MacroCallSections(project.Macros, project.MacroCalls, graphFormats)
+	markdownContents += ProgramSections(project.Programs)
+	markdownContents += LineageSections(project.Steps, model.LibraryPaths(project.Includes), graphFormats)
+	markdownContents += FormatSections(project.Formats)
+	markdownContents += ConfigurationSections(project.MacroVariables, project.MacroVariableReferences)
+	markdownContents += DataDictionarySections(project.Variables)
+	markdownContents += DatasetSections(project.Datasets, project.Steps, model.LibraryPaths(project.Includes))
+	markdownContents += NarrativeSections(comments)
+
+	//
+	// Normal comments
+	//
+	for i := 1; i <= len(keywordsMap); i++ {
+
+		currentKeyword := ""
+
+		for key, value := range keywordsMap {
+			if i == value {
+				currentKeyword = key
+			}
+		}
+
+		if currentKeyword != "" {
+			markdownContents += "\n# " + strings.Title(currentKeyword) + "\n\n"
+		}
+
+		counter := 1
+		for _, cmt := range comments {
+
+			// skip title comments
+			if cmt.Keyword != "" && strings.Index(cmt.Text, ":") == 0 {
+				continue
+			}
+
+			// skip if the comment is not associated with that keywords
+			trimmedKeyword := strings.TrimSpace(cmt.Keyword)
+			trimmedKeyword = strings.Trim(trimmedKeyword, "@")
+			if trimmedKeyword != currentKeyword {
+				continue
+			}
+
+			indexAsString := strconv.FormatInt(int64(cmt.Index), 10)
+			counterAsString := strconv.FormatInt(int64(counter), 10)
+			lineNumberAsString := strconv.FormatInt(int64(cmt.LineNum), 10)
+			if project.Languages[cmt.Filename] == model.LanguageStata {
+				indexAsString = "s" + indexAsString
+			}
+
+			// comments of a part of a file, such as a chunk, are located within that part
+			location := ":" + lineNumberAsString
+			if cmt.Part != "" {
+				location = " › " + cmt.Part + location
+			}
+
+			markdownContents += indexAsString + "." + counterAsString + location + " " + cmt.Text + "\n"
+
+			counter++
+		}
+	}
+
+	if _, err := io.WriteString(w, markdownContents); err != nil {
+		return err
+	}
+	return failures.Err()
+}
+
+// WriteDocumentation ... generate documentation using the comments and write it out to file
+//
+// When keeping going, the comments that could not be rendered are left out
+// and their errors returned as a |model.ErrorList| once everything else has
+// been written.
+func WriteDocumentation(docsDir string, files []string, graphFormats []string, keepGoing bool, project model.Project) error {
+
+	if docsDir == "" {
+		return fmt.Errorf("Docs directory name is invalid")
+	}
+
+	var markdownContents strings.Builder
+	failures := make(model.ErrorList, 0)
+	err := Markdown{GraphFormats: graphFormats, KeepGoing: keepGoing}.Render(&markdownContents, project)
+	if list, ok := err.(model.ErrorList); ok {
+		failures = list
+	} else if err != nil {
+		return err
+	}
+
+	// write out the contents to a markdown file, force overwrite at this time
+	for _, filename := range files {
+
+		currentFile := filepath.Join(docsDir, filename)
+
+		err := fileutils.WriteToFile(currentFile, markdownContents.String(), true)
+		if err != nil {
+			return err
+		}
+	}
+
+	// write out the graphs in any additional formats requested
+	graphs := []Graph{DependencyGraph(project.Includes), MacroCallGraph(project.MacroCalls)}
+	graphs = append(graphs, LineageGraphs(project.Steps)...)
+	if len(lineageFiles(project.Steps)) > 1 {
+		graphs = append(graphs, ProjectLineageGraph(project.Steps, model.LibraryPaths(project.Includes)))
+	}
+	for _, g := range graphs {
+		if len(g.Edges) < 1 {
+			continue
+		}
+		err := WriteGraph(docsDir, g, graphFormats)
+		if err != nil {
+			return err
+		}
+	}
+
+	// if got this far, everything that could be rendered was written
+	return failures.Err()
+}
@@ -0,0 +1,48 @@
+/*
+ * Functions for rendering the narrative of notebooks
+ */
+
+package render
+
+import (
+	"strconv"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// NarrativeSections ... generate the markdown section of the narrative of each notebook, in the order of its cells
+//
+// The paragraphs of the narrative are listed whether or not they are also
+// filed under a keyword, so that each notebook reads as it was written.
+func NarrativeSections(comments []model.Comment) string {
+
+	files := make([]string, 0)
+	narratives := make(map[string][]model.Comment)
+	for _, cmt := range comments {
+		if !cmt.Narrative {
+			continue
+		}
+		files = model.AppendUnique(files, cmt.Filename)
+		narratives[cmt.Filename] = append(narratives[cmt.Filename], cmt)
+	}
+
+	if len(files) < 1 {
+		return ""
+	}
+
+	markdownContents := "\n# Notebooks\n"
+
+	for _, filename := range files {
+
+		markdownContents += "\n## " + filename + "\n\n"
+		for _, cmt := range narratives[filename] {
+			location := cmt.Part + ":" + strconv.Itoa(cmt.LineNum)
+			if cmt.Part == "" {
+				location = strconv.Itoa(cmt.LineNum)
+			}
+			markdownContents += "* " + location + " " + cmt.Text + "\n"
+		}
+	}
+
+	return markdownContents
+}
@@ -0,0 +1,37 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+func TestMarkdownRendersNarrative(t *testing.T) {
+	project := model.Project{
+		Files: []string{"explore.ipynb", "prepare.py"},
+		Comments: []model.Comment{
+			{Filename: "explore.ipynb", Index: 1, Part: "cell 1", LineNum: 1, Text: "Exploring the visits", Narrative: true},
+			{Filename: "explore.ipynb", Index: 1, Part: "cell 1", LineNum: 3, Keyword: "@note ", Text: "Adults only", Narrative: true},
+			{Filename: "explore.ipynb", Index: 1, Part: "cell 2", LineNum: 1, Text: "a comment of the code"},
+			{Filename: "explore.ipynb", Index: 1, Part: "cell 3", LineNum: 2, Text: "Visits per patient", Narrative: true},
+			{Filename: "prepare.py", Index: 2, LineNum: 1, Keyword: "@note ", Text: "Prepares the data"},
+		},
+	}
+	var b strings.Builder
+	if err := (Markdown{}).Render(&b, project); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	got := b.String()
+
+	want := "\n# Notebooks\n\n## explore.ipynb\n\n" +
+		"* cell 1:1 Exploring the visits\n" +
+		"* cell 1:3 Adults only\n" +
+		"* cell 3:2 Visits per patient\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("Render() = %q, want the narrative %q", got, want)
+	}
+	if strings.Contains(got, "a comment of the code") {
+		t.Errorf("Render() = %q, want the comments of the code left out of the narrative", got)
+	}
+}
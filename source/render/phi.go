@@ -0,0 +1,51 @@
+/*
+ * Functions for reporting the identifiers found in the comments of a project
+ */
+
+package render
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/rbisewski/gommentary/source/fileutils"
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// IdentifierReport ... generate the markdown report of the identifiers found, without repeating them
+func IdentifierReport(findings []model.Finding) string {
+
+	markdownContents := "% Identifiers found in comments\n\n"
+	if len(findings) < 1 {
+		return markdownContents + "No identifiers were found.\n"
+	}
+
+	counts := make(map[string]int)
+	order := make([]string, 0)
+	for _, finding := range findings {
+		if counts[finding.Detector] == 0 {
+			order = append(order, finding.Detector)
+		}
+		counts[finding.Detector]++
+	}
+	for _, name := range order {
+		markdownContents += "* " + name + ": " + strconv.Itoa(counts[name]) + "\n"
+	}
+
+	markdownContents += "\n| Detector | File | Line | Action |\n|---|---|---|---|\n"
+	for _, finding := range findings {
+		action := "flagged"
+		if finding.Redacted {
+			action = "redacted"
+		}
+		markdownContents += "| " + finding.Detector + " | " + finding.Filename + " | " +
+			strconv.Itoa(finding.LineNum) + " | " + action + " |\n"
+	}
+
+	return markdownContents
+}
+
+// WriteIdentifierReport ... write the report of the identifiers found out to the docs directory
+func WriteIdentifierReport(docsDir string, filename string, findings []model.Finding) error {
+	return fileutils.WriteToFile(filepath.Join(docsDir, filename), IdentifierReport(findings), true)
+}
@@ -0,0 +1,78 @@
+/*
+ * Functions for rendering the documentation of Stata programs
+ */
+
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/rbisewski/gommentary/source/model"
+)
+
+// ProgramSections ... generate the markdown reference section for each Stata program
+func ProgramSections(programs []model.Macro) string {
+
+	if len(programs) < 1 {
+		return ""
+	}
+
+	markdownContents := "\n# Stata programs\n"
+
+	for _, program := range programs {
+
+		markdownContents += "\n## " + program.Name + "\n\n"
+		markdownContents += fmt.Sprintf("Defined in %s:%d", program.Filename, program.LineNum)
+		if program.Properties != "" {
+			markdownContents += " (" + program.Properties + ")"
+		}
+		markdownContents += "\n"
+
+		if program.Summary != "" {
+			markdownContents += "\n" + program.Summary + "\n"
+		}
+
+		syntax := program.Syntax
+		if syntax == nil {
+			continue
+		}
+
+		markdownContents += "\n`" + syntax.Text + "`\n\n"
+		if syntax.Varlist != "" {
+			markdownContents += "* " + syntax.Varlist + ": " + syntax.VarlistRequirement + "\n"
+		}
+		if syntax.If != "" {
+			markdownContents += "* if: " + syntax.If + "\n"
+		}
+		if syntax.In != "" {
+			markdownContents += "* in: " + syntax.In + "\n"
+		}
+		if syntax.Using != "" {
+			markdownContents += "* using: " + syntax.Using + "\n"
+		}
+		if syntax.Exp != "" {
+			markdownContents += "* =exp: " + syntax.Exp + "\n"
+		}
+		if len(syntax.Weights) > 0 {
+			markdownContents += "* weights: " + strings.Join(syntax.Weights, ", ") + "\n"
+		}
+
+		if len(syntax.Options) < 1 {
+			continue
+		}
+
+		markdownContents += "\n| Option | Abbreviation | Type | Default | Required |\n"
+		markdownContents += "|---|---|---|---|---|\n"
+		for _, option := range syntax.Options {
+			required := "no"
+			if option.Required {
+				required = "yes"
+			}
+			markdownContents += "| " + strings.ToLower(option.Name) + " | " + option.Abbreviation + " | " +
+				option.Type + " | " + option.Default + " | " + required + " |\n"
+		}
+	}
+
+	return markdownContents
+}
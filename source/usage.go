@@ -2,14 +2,40 @@ package main
 
 // TODO: finalize this once the program is complete
 const usageMessage = `
-Documentation generator for SAS and Stata code, written in golang.
+Documentation generator for SAS, Stata, R, SPSS, SQL and Python code, written in golang.
 
 Usage: identify_conditions
        -code-dir /path/to/application/code
        -docs-dir /path/to/application/code/docs
+       -graph-formats text,dot,mermaid
+       -dictionary /path/to/data/dictionary.csv
+       -data-dir /path/to/data
+       -audit
+       -phi flag|redact
+       -phi-patterns /path/to/patterns.txt
+       -keep-going
 
 Arguments:
 	h, help       Prints this usage message
   	version       Prints the current program version and build info
-	code-dir      Path to the directory containing SAS / Stata code.
-	docs-dir      Path to the folder which will store the generated docs.`
+	code-dir      Path to the directory containing the code to document.
+	docs-dir      Path to the folder which will store the generated docs.
+	graph-formats Comma separated list of graph formats to generate; text
+	              graphs are part of the docs, whereas dot and mermaid
+	              graphs are written to separate files. Default: text
+	dictionary    Path to a data dictionary CSV, with a header row naming
+	              the name, dataset, type, label, description and codes
+	              columns, to reconcile with the @var comments.
+	data-dir      Path to the directory containing the Stata and SAS
+	              datasets of the project, whose variables are to be
+	              documented.
+	audit         Also write an audit report, output-audit.md, listing the
+	              external commands and absolute paths found in live code.
+	phi           Whether to flag or redact identifiers, such as email
+	              addresses, phone, health card numbers and dates of
+	              birth, found in comments; either way output-phi.md
+	              reports where they were found. Default: off
+	phi-patterns  Path to a file of additional identifier patterns, one
+	              "name = regex" per line.
+	keep-going    Document the files that could be read even if others
+	              could not, then list the errors and exit with code 1.`